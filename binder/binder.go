@@ -0,0 +1,432 @@
+// Package binder fill request structs from url.Values, maps and gRPC metadata
+// The keys are json names, the fields of nested structs are joined by dot, e.g. inner.name,
+// the fields of embedded structs without json tags are promoted like encoding/json, see fieldaccess.JSONFields.
+// The plan of a struct type is built once and the fields are set by fieldaccess
+
+package binder
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go_tests/fieldaccess"
+
+	"github.com/spf13/cast"
+	"google.golang.org/grpc/metadata"
+)
+
+// FieldError the error of binding a key
+type FieldError struct {
+	Field string      // the key in the source
+	Value interface{} // the value in the source
+	Err   error
+}
+
+// Error implement error
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field(%s): %s", e.Field, e.Err.Error())
+}
+
+// Unwrap return the cause
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Errors the errors of all the keys which failed to bind, sorted by key
+type Errors []*FieldError
+
+// Error implement error
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// binding a bindable field
+type binding struct {
+	field *fieldaccess.Field
+	elem  reflect.Type // the element type of a slice field, nil otherwise
+}
+
+// plan the bindable fields of a struct type
+type plan struct {
+	desc     *fieldaccess.Descriptor
+	bindings map[string]*binding // json path -> binding
+	folded   map[string]*binding // lower case json path -> binding
+	prefixes map[string]bool     // json paths of nested structs
+}
+
+// plans *fieldaccess.Descriptor -> *plan
+var plans sync.Map
+
+// planOf return the cached plan of ptr's type
+func planOf(ptr interface{}) (*plan, error) {
+	desc, err := fieldaccess.Of(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := plans.Load(desc); ok {
+		return p.(*plan), nil
+	}
+	p := &plan{
+		desc:     desc,
+		bindings: make(map[string]*binding),
+		folded:   make(map[string]*binding),
+		prefixes: make(map[string]bool),
+	}
+	fields, err := desc.JSONFields()
+	if err != nil {
+		return nil, err
+	}
+	p.walk(fields, "")
+	actual, _ := plans.LoadOrStore(desc, p)
+	return actual.(*plan), nil
+}
+
+// walk collect the bindable fields, the fields of nested structs are prefixed by jsonPrefix
+func (p *plan) walk(fields []*fieldaccess.JSONField, jsonPrefix string) {
+	for _, jf := range fields {
+		path := jsonPrefix + jf.Name
+		if jf.Nested {
+			// a recursive type is not walked through again
+			if jf.Fields != nil {
+				p.prefixes[path] = true
+				p.walk(jf.Fields, path+".")
+			}
+			continue
+		}
+		b := &binding{field: jf.Field}
+		if jf.Field.Kind() == reflect.Slice && jf.Field.Type.Elem().Kind() != reflect.Uint8 {
+			b.elem = jf.Field.Type.Elem()
+		}
+		p.bindings[path] = b
+		p.folded[strings.ToLower(path)] = b
+	}
+}
+
+// lookup return the binding of key, fold ignores the case of key
+func (p *plan) lookup(key string, fold bool) *binding {
+	if b, ok := p.bindings[key]; ok {
+		return b
+	}
+	if fold {
+		return p.folded[strings.ToLower(key)]
+	}
+	return nil
+}
+
+// BindValues fill the struct pointed by ptr from url values, such as a query string or a form,
+// a scalar field takes the first value, see bindStrings for slice fields.
+// The keys which are not bindable are ignored, the failed fields are returned as Errors
+func BindValues(ptr interface{}, values url.Values) error {
+	return bindStrings(ptr, values, false)
+}
+
+// BindMetadata fill the struct pointed by ptr from gRPC metadata like BindValues,
+// the keys of metadata are lower case so they match json names ignoring case
+func BindMetadata(ptr interface{}, md metadata.MD) error {
+	return bindStrings(ptr, md, true)
+}
+
+// BindStrings fill the struct pointed by ptr from a map of strings like BindValues
+func BindStrings(ptr interface{}, m map[string]string) error {
+	values := make(url.Values, len(m))
+	for k, v := range m {
+		values[k] = []string{v}
+	}
+	return bindStrings(ptr, values, false)
+}
+
+// bindStrings bind the values of each key, a slice field takes all the values,
+// a single value of a slice field is split by comma, e.g. ids=1,2,3
+func bindStrings(ptr interface{}, values map[string][]string, fold bool) error {
+	p, err := planOf(ptr)
+	if err != nil {
+		return err
+	}
+	if reflect.ValueOf(ptr).IsNil() {
+		return fmt.Errorf("param ptr is a nil pointer")
+	}
+	var errs Errors
+	for _, key := range sortedKeys(values) {
+		vs := values[key]
+		b := p.lookup(key, fold)
+		if b == nil || len(vs) == 0 {
+			continue
+		}
+		var value interface{} = vs[0]
+		if b.elem != nil {
+			if len(vs) == 1 {
+				vs = splitComma(vs[0])
+			}
+			value = vs
+		}
+		if err = b.bind(ptr, value); err != nil {
+			errs = append(errs, &FieldError{Field: key, Value: value, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// BindMap fill the struct pointed by ptr from a map, such as a decoded json object,
+// a nested struct is filled from either a nested map or the dotted keys, the values are converted by cast.
+// The keys which are not bindable are ignored, the failed fields are returned as Errors
+func BindMap(ptr interface{}, m map[string]interface{}) error {
+	p, err := planOf(ptr)
+	if err != nil {
+		return err
+	}
+	if reflect.ValueOf(ptr).IsNil() {
+		return fmt.Errorf("param ptr is a nil pointer")
+	}
+	var errs Errors
+	p.bindMap(ptr, "", m, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// bindMap bind the values of m whose keys are prefixed by prefix
+func (p *plan) bindMap(ptr interface{}, prefix string, m map[string]interface{}, errs *Errors) {
+	for _, key := range sortedKeys(m) {
+		value, path := m[key], prefix+key
+		if b := p.lookup(path, false); b != nil {
+			if err := b.bind(ptr, value); err != nil {
+				*errs = append(*errs, &FieldError{Field: path, Value: value, Err: err})
+			}
+			continue
+		}
+		if sub, ok := value.(map[string]interface{}); ok && p.prefixes[path] {
+			p.bindMap(ptr, path+".", sub, errs)
+		}
+	}
+}
+
+// sortedKeys the keys of m in order, so the errors are reported in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// bind convert value and set the field, the scalar kinds are set by the typed setters
+func (b *binding) bind(ptr interface{}, value interface{}) error {
+	f := b.field
+	if b.elem != nil {
+		v, err := convertSlice(value, f.Type)
+		if err != nil {
+			return err
+		}
+		return f.Set(ptr, v.Interface())
+	}
+	if f.Type == fieldaccess.TimeType {
+		t, err := cast.ToTimeE(value)
+		if err != nil {
+			return err
+		}
+		return f.Set(ptr, t)
+	}
+	switch f.Kind() {
+	case reflect.Bool:
+		v, err := cast.ToBoolE(value)
+		if err != nil {
+			return err
+		}
+		f.SetBool(ptr, v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := toInt(value, f.Type)
+		if err != nil {
+			return err
+		}
+		f.SetInt(ptr, v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v, err := toUint(value, f.Type)
+		if err != nil {
+			return err
+		}
+		f.SetUint(ptr, v)
+	case reflect.Float32, reflect.Float64:
+		v, err := toFloat(value, f.Type)
+		if err != nil {
+			return err
+		}
+		f.SetFloat(ptr, v)
+	case reflect.String:
+		v, err := cast.ToStringE(value)
+		if err != nil {
+			return err
+		}
+		f.SetString(ptr, v)
+	default:
+		v, err := convert(value, f.Type)
+		if err != nil {
+			return err
+		}
+		return f.Set(ptr, v.Interface())
+	}
+	return nil
+}
+
+// checkIntegral check that a float value has no fraction, cast would truncate 3.9 to 3
+func checkIntegral(value interface{}) error {
+	switch v := value.(type) {
+	case float32, float64:
+		if f := reflect.ValueOf(v).Float(); f != math.Trunc(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("value(%v) is not an integer", v)
+		}
+	}
+	return nil
+}
+
+// toInt convert value to an integer which does not overflow typ,
+// strings are parsed in base 10, cast would parse "010" as octal 8
+func toInt(value interface{}, typ reflect.Type) (int64, error) {
+	var v int64
+	var err error
+	if str, ok := value.(string); ok {
+		v, err = strconv.ParseInt(str, 10, 64)
+	} else if err = checkIntegral(value); err == nil {
+		v, err = cast.ToInt64E(value)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if reflect.Zero(typ).OverflowInt(v) {
+		return 0, fmt.Errorf("value(%d) overflows %s", v, typ)
+	}
+	return v, nil
+}
+
+// toUint convert value to an unsigned integer which does not overflow typ, strings are parsed in base 10
+func toUint(value interface{}, typ reflect.Type) (uint64, error) {
+	var v uint64
+	var err error
+	if str, ok := value.(string); ok {
+		v, err = strconv.ParseUint(str, 10, 64)
+	} else if err = checkIntegral(value); err == nil {
+		v, err = cast.ToUint64E(value)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if reflect.Zero(typ).OverflowUint(v) {
+		return 0, fmt.Errorf("value(%d) overflows %s", v, typ)
+	}
+	return v, nil
+}
+
+// toFloat convert value to a float which does not overflow typ
+func toFloat(value interface{}, typ reflect.Type) (float64, error) {
+	v, err := cast.ToFloat64E(value)
+	if err != nil {
+		return 0, err
+	}
+	if reflect.Zero(typ).OverflowFloat(v) {
+		return 0, fmt.Errorf("value(%v) overflows %s", v, typ)
+	}
+	return v, nil
+}
+
+// convert value to typ by reflect, used for the elements of slices and the other kinds
+func convert(value interface{}, typ reflect.Type) (reflect.Value, error) {
+	v := reflect.New(typ).Elem()
+	if value == nil {
+		return v, nil
+	}
+	if rv := reflect.ValueOf(value); rv.Type().AssignableTo(typ) {
+		v.Set(rv)
+		return v, nil
+	}
+	if typ == fieldaccess.TimeType {
+		t, err := cast.ToTimeE(value)
+		if err != nil {
+			return v, err
+		}
+		v.Set(reflect.ValueOf(t))
+		return v, nil
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		b, err := cast.ToBoolE(value)
+		if err != nil {
+			return v, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toInt(value, typ)
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := toUint(value, typ)
+		if err != nil {
+			return v, err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := toFloat(value, typ)
+		if err != nil {
+			return v, err
+		}
+		v.SetFloat(n)
+	case reflect.String:
+		s, err := cast.ToStringE(value)
+		if err != nil {
+			return v, err
+		}
+		v.SetString(s)
+	case reflect.Slice:
+		return convertSlice(value, typ)
+	default:
+		return v, fmt.Errorf("value of %T can not be bound to %s", value, typ)
+	}
+	return v, nil
+}
+
+// convertSlice convert a slice or a single value to the slice type typ, each element is converted by convert
+func convertSlice(value interface{}, typ reflect.Type) (reflect.Value, error) {
+	rv := reflect.ValueOf(value)
+	if value == nil {
+		return reflect.Zero(typ), nil
+	}
+	if rv.Type().AssignableTo(typ) {
+		return rv, nil
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		rv = reflect.ValueOf([]interface{}{value})
+	}
+	v := reflect.MakeSlice(typ, rv.Len(), rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		elem, err := convert(rv.Index(i).Interface(), typ.Elem())
+		if err != nil {
+			return v, fmt.Errorf("[%d]: %s", i, err.Error())
+		}
+		v.Index(i).Set(elem)
+	}
+	return v, nil
+}
+
+// splitComma split s by comma and trim the spaces of each part
+func splitComma(s string) []string {
+	parts := strings.Split(s, ",")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts
+}
@@ -0,0 +1,433 @@
+// Package cli the searchfile command, see cmd/searchfile
+// The rows are streamed, only the first -sample rows are kept in memory to infer the schema.
+// The columns of jsonl written as csv or table are the keys of the sample rows too,
+// a matched row with a key out of them is an error, increase -sample in that case
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go_tests/search"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	FORMAT_CSV   = "csv"
+	FORMAT_JSONL = "jsonl"
+	FORMAT_TABLE = "table"
+)
+
+// tableFlushRows the table is flushed every tableFlushRows rows,
+// so the width of columns is aligned within a block of rows
+const tableFlushRows = 1000
+
+// row a row of the input, the raw record or line is kept for writing back unchanged
+type row struct {
+	values map[string]interface{}
+	record []string // csv
+	line   []byte   // jsonl
+	lineNo int      // jsonl
+}
+
+// rowReader read rows one by one, io.EOF is returned at the end
+type rowReader interface {
+	read() (*row, error)
+	columns() []string // nil if the columns are unknown until rows are read
+}
+
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %s", err.Error())
+	}
+	return &csvReader{r: cr, header: header}, nil
+}
+
+func (c *csvReader) read() (*row, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{}, len(c.header))
+	for i, name := range c.header {
+		values[name] = record[i]
+	}
+	return &row{values: values, record: record}, nil
+}
+
+func (c *csvReader) columns() []string {
+	return c.header
+}
+
+type jsonlReader struct {
+	r      *bufio.Reader
+	lineNo int
+}
+
+func (j *jsonlReader) read() (*row, error) {
+	for {
+		line, err := j.r.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, err
+		}
+		j.lineNo++
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		values := make(map[string]interface{})
+		if err = dec.Decode(&values); err != nil {
+			return nil, fmt.Errorf("line %d is invalid json: %s", j.lineNo, err.Error())
+		}
+		return &row{values: values, line: line, lineNo: j.lineNo}, nil
+	}
+}
+
+func (j *jsonlReader) columns() []string {
+	return nil
+}
+
+// rowWriter write the matched rows
+type rowWriter interface {
+	write(r *row) error
+	flush() error
+}
+
+// formatValue the text of a value in csv and table
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
+
+// fields the texts of the row's columns
+func fields(r *row, columns []string) []string {
+	if r.record != nil {
+		return r.record
+	}
+	texts := make([]string, len(columns))
+	for i, name := range columns {
+		texts[i] = formatValue(r.values[name])
+	}
+	return texts
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw, columns: columns}, nil
+}
+
+func (c *csvWriter) write(r *row) error {
+	return c.w.Write(fields(r, c.columns))
+}
+
+func (c *csvWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlWriter struct {
+	w       *bufio.Writer
+	columns []string
+}
+
+func (j *jsonlWriter) write(r *row) error {
+	if r.line != nil {
+		j.w.Write(r.line)
+		return j.w.WriteByte('\n')
+	}
+	// the keys are written in the order of csv header
+	j.w.WriteByte('{')
+	for i, name := range j.columns {
+		if i > 0 {
+			j.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		value, _ := json.Marshal(r.record[i])
+		j.w.Write(key)
+		j.w.WriteByte(':')
+		j.w.Write(value)
+	}
+	j.w.WriteByte('}')
+	return j.w.WriteByte('\n')
+}
+
+func (j *jsonlWriter) flush() error {
+	return j.w.Flush()
+}
+
+type tableWriter struct {
+	w       *tabwriter.Writer
+	columns []string
+	rows    int
+}
+
+func newTableWriter(w io.Writer, columns []string) *tableWriter {
+	t := &tableWriter{w: tabwriter.NewWriter(w, 0, 4, 2, ' ', 0), columns: columns}
+	fmt.Fprintln(t.w, strings.Join(columns, "\t"))
+	return t
+}
+
+func (t *tableWriter) write(r *row) error {
+	texts := fields(r, t.columns)
+	for i, text := range texts { // tab and newline break the table
+		texts[i] = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(text)
+	}
+	if _, err := fmt.Fprintln(t.w, strings.Join(texts, "\t")); err != nil {
+		return err
+	}
+	if t.rows++; t.rows%tableFlushRows == 0 {
+		return t.w.Flush()
+	}
+	return nil
+}
+
+func (t *tableWriter) flush() error {
+	return t.w.Flush()
+}
+
+// formatOf the format of file by its extension
+func formatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		return FORMAT_JSONL
+	}
+	return FORMAT_CSV
+}
+
+// loadSchema load the schema file by its extension
+func loadSchema(path string) (*search.Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		return search.LoadSchemaJSON(data)
+	}
+	return search.LoadSchemaYAML(data)
+}
+
+// checkColumns check whether the keys of the jsonl row are in the columns of csv or table output
+func checkColumns(r *row, columns map[string]bool) error {
+	for key := range r.values {
+		if !columns[key] {
+			return fmt.Errorf("line %d has key(%s) which is not in the sample rows, increase -sample", r.lineNo, key)
+		}
+	}
+	return nil
+}
+
+// sampleColumns the sorted keys of the sample rows
+func sampleColumns(samples []*row) []string {
+	keys := make(map[string]bool)
+	for _, r := range samples {
+		for key := range r.values {
+			keys[key] = true
+		}
+	}
+	columns := make([]string, 0, len(keys))
+	for key := range keys {
+		columns = append(columns, key)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// FlagError an invalid flag or argument, the command exits with 2 like the flag package
+type FlagError struct {
+	Err error
+}
+
+func (e *FlagError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap return the error of the flag
+func (e *FlagError) Unwrap() error {
+	return e.Err
+}
+
+// flagErrorf format a FlagError
+func flagErrorf(format string, a ...interface{}) error {
+	return &FlagError{Err: fmt.Errorf(format, a...)}
+}
+
+// Run run the command with args(without the program name), the rows are read from stdin when -in is -
+// and the matched rows are written to stdout, flag.ErrHelp is returned for -h
+func Run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("searchfile", flag.ContinueOnError)
+	in := flags.String("in", "-", "input file, - is stdin")
+	format := flags.String("format", "", "input format csv/jsonl, detected by the extension of input file by default")
+	schemaPath := flags.String("schema", "", "schema file(yaml or json), inferred from the sample rows by default")
+	query := flags.String("query", "", "query string, e.g. age >= 18 and name contain tom")
+	out := flags.String("out", "", "output format csv/jsonl/table, the same as input format by default")
+	sample := flags.Int("sample", 100, "the number of rows to infer the schema and the columns of jsonl")
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return err
+	} else if err != nil {
+		return &FlagError{Err: err}
+	}
+	if flags.NArg() > 0 {
+		return flagErrorf("unexpected arguments %v", flags.Args())
+	}
+
+	input := stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+		if *format == "" {
+			*format = formatOf(*in)
+		}
+	}
+	if *format == "" {
+		*format = FORMAT_CSV
+	}
+	if *out == "" {
+		*out = *format
+	}
+
+	var reader rowReader
+	switch *format {
+	case FORMAT_CSV:
+		cr, err := newCSVReader(bufio.NewReaderSize(input, 1<<20))
+		if err != nil {
+			return err
+		}
+		reader = cr
+	case FORMAT_JSONL:
+		reader = &jsonlReader{r: bufio.NewReaderSize(input, 1<<20)}
+	default:
+		return flagErrorf("not support input format(%s)", *format)
+	}
+
+	// the sample rows are read ahead to infer the schema and the columns of jsonl
+	var samples []*row
+	for len(samples) < *sample {
+		r, err := reader.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		samples = append(samples, r)
+	}
+
+	var schema *search.Schema
+	if *schemaPath != "" {
+		var err error
+		if schema, err = loadSchema(*schemaPath); err != nil {
+			return err
+		}
+	} else {
+		values := make([]map[string]interface{}, len(samples))
+		for i, r := range samples {
+			values[i] = r.values
+		}
+		schema = search.InferSchema(values)
+	}
+	limit, err := search.NewSearcherLimitFromSchema(schema)
+	if err != nil {
+		return err
+	}
+	searchers, err := search.ParseQuery(*query)
+	if err != nil {
+		return err
+	}
+	compiled, err := limit.Compile(searchers)
+	if err != nil {
+		return err
+	}
+
+	columns := reader.columns()
+	var known map[string]bool // the columns which the keys of jsonl rows are checked against
+	if columns == nil {
+		columns = sampleColumns(samples)
+		if *out != FORMAT_JSONL {
+			known = make(map[string]bool, len(columns))
+			for _, column := range columns {
+				known[column] = true
+			}
+		}
+	}
+	output := bufio.NewWriterSize(stdout, 1<<16)
+	var writer rowWriter
+	switch *out {
+	case FORMAT_CSV:
+		if writer, err = newCSVWriter(output, columns); err != nil {
+			return err
+		}
+	case FORMAT_JSONL:
+		writer = &jsonlWriter{w: output, columns: columns}
+	case FORMAT_TABLE:
+		writer = newTableWriter(output, columns)
+	default:
+		return flagErrorf("not support output format(%s)", *out)
+	}
+
+	for i := 0; ; i++ {
+		var r *row
+		if i < len(samples) {
+			r = samples[i]
+			samples[i] = nil
+		} else if r, err = reader.read(); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if !compiled.MatchMap(r.values) {
+			continue
+		}
+		if known != nil {
+			if err = checkColumns(r, known); err != nil {
+				return err
+			}
+		}
+		if err = writer.write(r); err != nil {
+			return err
+		}
+	}
+	if err = writer.flush(); err != nil {
+		return err
+	}
+	return output.Flush()
+}
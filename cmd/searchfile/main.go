@@ -0,0 +1,26 @@
+// searchfile filter the rows of a CSV or JSON Lines file by a query string
+// e.g. searchfile -in users.csv -query 'age >= 18 and city in shanghai,beijing' -out table
+// The flags and the output are handled by package cli, the exit code is 0 for -h, 2 for bad flags and 1 for other errors
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go_tests/cmd/searchfile/cli"
+	"os"
+)
+
+func main() {
+	err := cli.Run(os.Args[1:], os.Stdin, os.Stdout)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	fmt.Fprintln(os.Stderr, err.Error())
+	var flagErr *cli.FlagError
+	if errors.As(err, &flagErr) {
+		os.Exit(2)
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,286 @@
+// searchgen generate reflection-free matchers, validators and sorters for structs with search tags
+// Usage: //go:generate go run go_tests/cmd/searchgen -type SimpleStruct
+// The code of type T is written to t_search.go(lower case) in the directory of the package,
+// the generated code checks and converts the searchers by the search package,
+// so it returns the same results as the reflective Filter and Sort
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// numberTypes the supported number types, the string type is supported as well
+var numberTypes = map[string]bool{
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true,
+}
+
+// genField a searchable field of the struct
+type genField struct {
+	GoName    string   // name of the struct field
+	Name      string   // json name
+	Type      string   // the basic go type, the underlying type of a named type
+	Named     bool     // the field's type is a named type, its value is converted to Type
+	Operators []string // search tag
+	Tokenizer string
+	Boost     string // a go float literal, empty means the default boost
+}
+
+// genStruct a struct to generate code for
+type genStruct struct {
+	Name   string
+	Var    string // the prefix of unexported identifiers
+	Fields []*genField
+}
+
+// IsString whether the field is a string, the other fields are numbers
+func (f *genField) IsString() bool {
+	return f.Type == "string"
+}
+
+// Value the expression of the field's value of data in Type
+func (f *genField) Value(data string) string {
+	if f.Named {
+		return f.Type + "(" + data + "." + f.GoName + ")"
+	}
+	return data + "." + f.GoName
+}
+
+// parseStruct collect the searchable fields of the struct type,
+// a field is searchable when both search and json tags exist, the same as NewSearcherLimit,
+// a named type such as type Status int32 is resolved to its underlying type
+func parseStruct(name string, st *types.Struct) (*genStruct, error) {
+	gs := &genStruct{Name: name, Var: strings.ToLower(name[:1]) + name[1:]}
+	goNames := make(map[string]string) // json name -> go name
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if field.Embedded() {
+			continue
+		}
+		tag := reflect.StructTag(st.Tag(i))
+		searchTag, jsonTag := tag.Get("search"), tag.Get("json")
+		if searchTag == "" || jsonTag == "" {
+			continue
+		}
+		// the names of A, B int are separate fields sharing the tag
+		if goName, ok := goNames[jsonTag]; ok {
+			return nil, fmt.Errorf("%s.%s: json name(%s) is used by %s", name, field.Name(), jsonTag, goName)
+		}
+		goNames[jsonTag] = field.Name()
+		basic, ok := field.Type().Underlying().(*types.Basic)
+		if !ok || (basic.Name() != "string" && !numberTypes[basic.Name()]) {
+			return nil, fmt.Errorf("%s.%s: only number and string types are supported", name, field.Name())
+		}
+		_, named := field.Type().(*types.Named)
+		gf := &genField{
+			GoName: field.Name(), Name: jsonTag, Type: basic.Name(), Named: named,
+			Operators: strings.Split(searchTag, ","), Tokenizer: tag.Get("tokenizer"),
+		}
+		if boost := tag.Get("boost"); boost != "" {
+			if _, err := strconv.ParseFloat(boost, 64); err != nil {
+				return nil, fmt.Errorf("%s.%s: invalid boost(%s)", name, gf.GoName, boost)
+			}
+			gf.Boost = boost
+		}
+		gs.Fields = append(gs.Fields, gf)
+	}
+	return gs, nil
+}
+
+// findStructs find the struct types of names in the package of dir, the package is type checked
+// to resolve named types, the errors of the other declarations are ignored
+func findStructs(dir string, names []string) (pkgName string, structs []*genStruct, err error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && !strings.HasSuffix(fi.Name(), "_search.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return "", nil, err
+	}
+	var files []*ast.File
+	for _, pkg := range pkgs {
+		pkgName = pkg.Name
+		for _, file := range pkg.Files {
+			files = append(files, file)
+		}
+	}
+	conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+	pkg, _ := conf.Check(pkgName, fset, files, nil)
+	for _, name := range names {
+		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			return "", nil, fmt.Errorf("type %s is not found", name)
+		}
+		st, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			return "", nil, fmt.Errorf("type %s is not a struct", name)
+		}
+		gs, err := parseStruct(name, st)
+		if err != nil {
+			return "", nil, err
+		}
+		structs = append(structs, gs)
+	}
+	return pkgName, structs, nil
+}
+
+var codeTemplate = template.Must(template.New("search").Parse(`// Code generated by searchgen -type {{.Struct.Name}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+	"go_tests/search"
+	"sort"
+)
+
+{{$s := .Struct -}}
+// {{$s.Var}}SearchLimit the limit built from the search tags of {{$s.Name}}
+var {{$s.Var}}SearchLimit, {{$s.Var}}SearchLimitErr = search.NewSearcherLimitFromSchema(&search.Schema{
+	Fields: []search.SchemaField{
+{{- range $s.Fields}}
+		{Name: {{printf "%q" .Name}}, Type: {{printf "%q" .Type}}, Operators: []string{ {{- range $i, $op := .Operators}}{{if $i}}, {{end}}{{printf "%q" $op}}{{end -}} }
+			{{- if .Tokenizer}}, Tokenizer: {{printf "%q" .Tokenizer}}{{end}}{{if .Boost}}, Boost: {{.Boost}}{{end}}},
+{{- end}}
+	},
+})
+
+// {{$s.Name}}Matcher the compiled searchers of {{$s.Name}}, safe for concurrent use
+type {{$s.Name}}Matcher struct {
+	conds []func(data *{{$s.Name}}) bool
+}
+
+// New{{$s.Name}}Matcher check the searchers by the search tags of {{$s.Name}} and compile them
+func New{{$s.Name}}Matcher(searchers []*search.Searcher) (*{{$s.Name}}Matcher, error) {
+	if {{$s.Var}}SearchLimitErr != nil {
+		return nil, {{$s.Var}}SearchLimitErr
+	}
+	query, err := {{$s.Var}}SearchLimit.Compile(searchers)
+	if err != nil {
+		return nil, err
+	}
+	m := &{{$s.Name}}Matcher{}
+	for _, c := range query.GenConditions() {
+		c := c
+		switch c.Field() {
+{{- range $s.Fields}}
+		case {{printf "%q" .Name}}:
+			m.conds = append(m.conds, func(data *{{$s.Name}}) bool {
+				return {{if .IsString}}search.GenMatchString{{else}}search.GenMatchNumber{{end}}({{.Value "data"}}, c)
+			})
+{{- end}}
+		}
+	}
+	return m, nil
+}
+
+// Validate{{$s.Name}}Searchers check the searchers by the search tags of {{$s.Name}}
+func Validate{{$s.Name}}Searchers(searchers []*search.Searcher) error {
+	_, err := New{{$s.Name}}Matcher(searchers)
+	return err
+}
+
+// Match check whether data meets all the searchers
+func (m *{{$s.Name}}Matcher) Match(data *{{$s.Name}}) bool {
+	for _, cond := range m.conds {
+		if !cond(data) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter return the datas which meet all the searchers in input order
+func (m *{{$s.Name}}Matcher) Filter(datasIn []*{{$s.Name}}) (datasOut []*{{$s.Name}}, err error) {
+	for i, data := range datasIn {
+		if data == nil {
+			return nil, fmt.Errorf("datasIn[%d] is a nil pointer", i)
+		}
+		if m.Match(data) {
+			datasOut = append(datasOut, data)
+		}
+	}
+	return datasOut, nil
+}
+
+// Sort{{$s.Name}} sort datas by the value of a searchable field stably,
+// from small to large or from large to small if desc
+func Sort{{$s.Name}}(datas []*{{$s.Name}}, field string, desc bool) error {
+	var less func(left, right *{{$s.Name}}) bool
+	switch field {
+{{- range $s.Fields}}
+	case {{printf "%q" .Name}}:
+		less = func(left, right *{{$s.Name}}) bool { return left.{{.GoName}} < right.{{.GoName}} }
+{{- end}}
+	default:
+		return fmt.Errorf("field(%s) does not support sort", field)
+	}
+	for i, data := range datas {
+		if data == nil {
+			return fmt.Errorf("datasIn[%d] is a nil pointer", i)
+		}
+	}
+	sort.SliceStable(datas, func(i, j int) bool {
+		if desc {
+			return less(datas[j], datas[i])
+		}
+		return less(datas[i], datas[j])
+	})
+	return nil
+}
+`))
+
+// generate write the code of gs to the file in dir
+func generate(dir, pkgName string, gs *genStruct) error {
+	var buf bytes.Buffer
+	if err := codeTemplate.Execute(&buf, map[string]interface{}{"Package": pkgName, "Struct": gs}); err != nil {
+		return err
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format the code of %s: %s", gs.Name, err.Error())
+	}
+	return os.WriteFile(filepath.Join(dir, strings.ToLower(gs.Name)+"_search.go"), code, 0644)
+}
+
+func run() error {
+	types := flag.String("type", "", "comma-separated struct names")
+	dir := flag.String("dir", ".", "the directory of the package")
+	flag.Parse()
+	if *types == "" {
+		return fmt.Errorf("flag -type is required")
+	}
+	pkgName, structs, err := findStructs(*dir, strings.Split(*types, ","))
+	if err != nil {
+		return err
+	}
+	for _, gs := range structs {
+		if err = generate(*dir, pkgName, gs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "searchgen:", err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,198 @@
+// Typed accessors of Field
+// Get/Set work for any kind through reflect.NewAt,
+// the typed accessors read and write the field directly and are much faster
+
+package fieldaccess
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// Get return the value of the field in data, the zero value if a pointer on the path is nil
+func (f *Field) Get(data interface{}) interface{} {
+	p := f.pointer(data, false)
+	if p == nil {
+		return reflect.Zero(f.Type).Interface()
+	}
+	return reflect.NewAt(f.Type, p).Elem().Interface()
+}
+
+// Set set the field in data to value, value must be assignable to the field's type,
+// nil sets the field to its zero value
+func (f *Field) Set(data interface{}, value interface{}) error {
+	v := reflect.Zero(f.Type)
+	if value != nil {
+		v = reflect.ValueOf(value)
+		if !v.Type().AssignableTo(f.Type) {
+			return fmt.Errorf("value of %s is not assignable to field(%s) of %s", v.Type(), f.Path, f.Type)
+		}
+	}
+	reflect.NewAt(f.Type, f.pointer(data, true)).Elem().Set(v)
+	return nil
+}
+
+// GetInt return the value of the signed integer field
+func (f *Field) GetInt(data interface{}) int64 {
+	p := f.pointer(data, false)
+	if p == nil {
+		if f.kind < reflect.Int || f.kind > reflect.Int64 {
+			f.panicKind("GetInt")
+		}
+		return 0
+	}
+	switch f.kind {
+	case reflect.Int:
+		return int64(*(*int)(p))
+	case reflect.Int8:
+		return int64(*(*int8)(p))
+	case reflect.Int16:
+		return int64(*(*int16)(p))
+	case reflect.Int32:
+		return int64(*(*int32)(p))
+	case reflect.Int64:
+		return *(*int64)(p)
+	}
+	f.panicKind("GetInt")
+	return 0
+}
+
+// SetInt set the signed integer field, the value is truncated to the field's size like reflect
+func (f *Field) SetInt(data interface{}, value int64) {
+	if f.kind < reflect.Int || f.kind > reflect.Int64 {
+		f.panicKind("SetInt")
+	}
+	p := f.pointer(data, true)
+	switch f.kind {
+	case reflect.Int:
+		*(*int)(p) = int(value)
+	case reflect.Int8:
+		*(*int8)(p) = int8(value)
+	case reflect.Int16:
+		*(*int16)(p) = int16(value)
+	case reflect.Int32:
+		*(*int32)(p) = int32(value)
+	case reflect.Int64:
+		*(*int64)(p) = value
+	}
+}
+
+// GetUint return the value of the unsigned integer field
+func (f *Field) GetUint(data interface{}) uint64 {
+	p := f.pointer(data, false)
+	if p == nil {
+		if f.kind < reflect.Uint || f.kind > reflect.Uintptr {
+			f.panicKind("GetUint")
+		}
+		return 0
+	}
+	switch f.kind {
+	case reflect.Uint:
+		return uint64(*(*uint)(p))
+	case reflect.Uint8:
+		return uint64(*(*uint8)(p))
+	case reflect.Uint16:
+		return uint64(*(*uint16)(p))
+	case reflect.Uint32:
+		return uint64(*(*uint32)(p))
+	case reflect.Uint64:
+		return *(*uint64)(p)
+	case reflect.Uintptr:
+		return uint64(*(*uintptr)(p))
+	}
+	f.panicKind("GetUint")
+	return 0
+}
+
+// SetUint set the unsigned integer field, the value is truncated to the field's size like reflect
+func (f *Field) SetUint(data interface{}, value uint64) {
+	if f.kind < reflect.Uint || f.kind > reflect.Uintptr {
+		f.panicKind("SetUint")
+	}
+	p := f.pointer(data, true)
+	switch f.kind {
+	case reflect.Uint:
+		*(*uint)(p) = uint(value)
+	case reflect.Uint8:
+		*(*uint8)(p) = uint8(value)
+	case reflect.Uint16:
+		*(*uint16)(p) = uint16(value)
+	case reflect.Uint32:
+		*(*uint32)(p) = uint32(value)
+	case reflect.Uint64:
+		*(*uint64)(p) = value
+	case reflect.Uintptr:
+		*(*uintptr)(p) = uintptr(value)
+	}
+}
+
+// GetFloat return the value of the float field
+func (f *Field) GetFloat(data interface{}) float64 {
+	if f.kind != reflect.Float32 && f.kind != reflect.Float64 {
+		f.panicKind("GetFloat")
+	}
+	p := f.pointer(data, false)
+	if p == nil {
+		return 0
+	}
+	if f.kind == reflect.Float32 {
+		return float64(*(*float32)(p))
+	}
+	return *(*float64)(p)
+}
+
+// SetFloat set the float field
+func (f *Field) SetFloat(data interface{}, value float64) {
+	if f.kind != reflect.Float32 && f.kind != reflect.Float64 {
+		f.panicKind("SetFloat")
+	}
+	p := f.pointer(data, true)
+	if f.kind == reflect.Float32 {
+		*(*float32)(p) = float32(value)
+		return
+	}
+	*(*float64)(p) = value
+}
+
+// GetString return the value of the string field
+func (f *Field) GetString(data interface{}) string {
+	if f.kind != reflect.String {
+		f.panicKind("GetString")
+	}
+	p := f.pointer(data, false)
+	if p == nil {
+		return ""
+	}
+	return *(*string)(p)
+}
+
+// SetString set the string field
+func (f *Field) SetString(data interface{}, value string) {
+	if f.kind != reflect.String {
+		f.panicKind("SetString")
+	}
+	*(*string)(f.pointer(data, true)) = value
+}
+
+// GetBool return the value of the bool field
+func (f *Field) GetBool(data interface{}) bool {
+	if f.kind != reflect.Bool {
+		f.panicKind("GetBool")
+	}
+	p := f.pointer(data, false)
+	return p != nil && *(*bool)(p)
+}
+
+// SetBool set the bool field
+func (f *Field) SetBool(data interface{}, value bool) {
+	if f.kind != reflect.Bool {
+		f.panicKind("SetBool")
+	}
+	*(*bool)(f.pointer(data, true)) = value
+}
+
+// Addr return the address of the field in data, nil if a pointer on the path is nil
+func (f *Field) Addr(data interface{}) unsafe.Pointer {
+	return f.pointer(data, false)
+}
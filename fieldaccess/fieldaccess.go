@@ -0,0 +1,148 @@
+// Package fieldaccess read and write struct fields by cached descriptors
+// The descriptor of a struct type locates a field by its path once,
+// then the field is accessed at the struct's address plus the cached offsets without reflect,
+// which is the fastest way measured by test/reflect_01..05
+
+package fieldaccess
+
+import (
+	"fmt"
+	"go_tests/internal/eface"
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// typeOf the address of the type information of data's dynamic type
+func typeOf(data interface{}) unsafe.Pointer {
+	return eface.Of(data).Typ
+}
+
+// Descriptor the field locator of a struct type, safe for concurrent use
+type Descriptor struct {
+	typ    reflect.Type   // pointer of struct
+	typPtr unsafe.Pointer // the address of typ's information, compared with the data's
+	fields sync.Map       // path -> *Field
+
+	jsonOnce   sync.Once
+	jsonFields []*JSONField
+	jsonErr    error
+}
+
+// descriptors the address of *T's type information -> *Descriptor
+var descriptors sync.Map
+
+// Of return the cached descriptor of ptr's type, ptr must be a pointer of struct and it can be nil
+func Of(ptr interface{}) (*Descriptor, error) {
+	typPtr := typeOf(ptr)
+	if d, ok := descriptors.Load(typPtr); ok {
+		return d.(*Descriptor), nil
+	}
+	typ := reflect.TypeOf(ptr)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("type(%v) is not a pointer of struct", typ)
+	}
+	d, _ := descriptors.LoadOrStore(typPtr, &Descriptor{typ: typ, typPtr: typPtr})
+	return d.(*Descriptor), nil
+}
+
+// For return the cached descriptor of *T like Of
+func For[T any]() (*Descriptor, error) {
+	return Of((*T)(nil))
+}
+
+// Type the struct type described by d
+func (d *Descriptor) Type() reflect.Type {
+	return d.typ.Elem()
+}
+
+// Field return the field located by path, path is the names of nested fields joined by dot, e.g. Inner.B,
+// the fields of embedded structs are promoted and pointers of struct are walked through
+func (d *Descriptor) Field(path string) (*Field, error) {
+	if f, ok := d.fields.Load(path); ok {
+		return f.(*Field), nil
+	}
+	f := &Field{Path: path, typPtr: d.typPtr}
+	typ := d.typ.Elem()
+	var offset uintptr
+	names := strings.Split(path, ".")
+	for k, name := range names {
+		if k > 0 {
+			if typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct {
+				f.offsets = append(f.offsets, offset)
+				f.elems = append(f.elems, typ.Elem())
+				typ, offset = typ.Elem(), 0
+			}
+			if typ.Kind() != reflect.Struct {
+				return nil, fmt.Errorf("field(%s) of %s is not a struct", strings.Join(names[:k], "."), d.Type())
+			}
+		}
+		sf, ok := typ.FieldByName(name)
+		if !ok {
+			return nil, fmt.Errorf("field(%s) is not found in %s", path, d.Type())
+		}
+		// a promoted field is reached through its embedded structs
+		for i, index := range sf.Index {
+			if i > 0 && typ.Kind() == reflect.Ptr {
+				f.offsets = append(f.offsets, offset)
+				f.elems = append(f.elems, typ.Elem())
+				typ, offset = typ.Elem(), 0
+			}
+			field := typ.Field(index)
+			offset += field.Offset
+			typ = field.Type
+		}
+	}
+	f.offsets = append(f.offsets, offset)
+	f.Type, f.kind = typ, typ.Kind()
+	actual, _ := d.fields.LoadOrStore(path, f)
+	return actual.(*Field), nil
+}
+
+// Field a field of a struct type located by Descriptor.Field, safe for concurrent use.
+// The accessors panic like reflect when data is not a non-nil pointer of the struct type
+// or the field's kind does not match the accessor
+type Field struct {
+	Path    string
+	Type    reflect.Type
+	kind    reflect.Kind
+	typPtr  unsafe.Pointer
+	offsets []uintptr      // offsets[i+1] is relative to the struct pointed by the field at offsets[i]
+	elems   []reflect.Type // elems[i] is the struct type pointed by the field at offsets[i]
+}
+
+// Kind the kind of the field's type
+func (f *Field) Kind() reflect.Kind {
+	return f.kind
+}
+
+// pointer the address of the field in data, nil if a pointer on the path is nil and alloc is false,
+// the nil pointers on the path are set to new structs if alloc is true
+func (f *Field) pointer(data interface{}, alloc bool) unsafe.Pointer {
+	inf := eface.Of(data)
+	if inf.Typ != f.typPtr {
+		panic(fmt.Sprintf("fieldaccess: %T is not the type of field(%s)", data, f.Path))
+	}
+	if inf.Value == nil {
+		panic(fmt.Sprintf("fieldaccess: access field(%s) of a nil pointer", f.Path))
+	}
+	p := inf.Value
+	last := len(f.offsets) - 1
+	for i, offset := range f.offsets[:last] {
+		pp := (*unsafe.Pointer)(unsafe.Pointer(uintptr(p) + offset))
+		if *pp == nil {
+			if !alloc {
+				return nil
+			}
+			*pp = reflect.New(f.elems[i]).UnsafePointer()
+		}
+		p = *pp
+	}
+	return unsafe.Pointer(uintptr(p) + f.offsets[last])
+}
+
+// panicKind panic because the accessor does not match the field's kind
+func (f *Field) panicKind(accessor string) {
+	panic(fmt.Sprintf("fieldaccess: %s of field(%s) whose kind is %s", accessor, f.Path, f.kind))
+}
@@ -0,0 +1,49 @@
+// JSON fields of a struct
+// The fields are listed by package jsonfield, which follows the rules of encoding/json,
+// every field is located by the descriptor so it is accessed by offsets
+
+package fieldaccess
+
+import (
+	"go_tests/jsonfield"
+)
+
+// TimeType the type of time.Time, which is encoded as a string rather than walked through
+var TimeType = jsonfield.TimeType
+
+// JSONField a field encoded by encoding/json
+type JSONField struct {
+	Name      string // json name
+	Field     *Field
+	Tagged    bool         // has a json tag
+	OmitEmpty bool         // omitted by encoding/json when it is empty
+	Nested    bool         // a struct or a pointer of struct other than time.Time
+	Fields    []*JSONField // the fields of a nested struct, nil if the type is recursive
+}
+
+// JSONFields return the cached json fields of the struct in the order of declaration
+func (d *Descriptor) JSONFields() ([]*JSONField, error) {
+	d.jsonOnce.Do(func() {
+		d.jsonFields, d.jsonErr = d.jsonFieldsOf(jsonfield.Of(d.Type()), "")
+	})
+	return d.jsonFields, d.jsonErr
+}
+
+// jsonFieldsOf locate the json fields, goPrefix ends with dot if not empty
+func (d *Descriptor) jsonFieldsOf(fields []*jsonfield.Field, goPrefix string) ([]*JSONField, error) {
+	out := make([]*JSONField, 0, len(fields))
+	for _, jf := range fields {
+		f, err := d.Field(goPrefix + jf.GoPath)
+		if err != nil {
+			return nil, err
+		}
+		field := &JSONField{Name: jf.Name, Field: f, Tagged: jf.Tagged, OmitEmpty: jf.OmitEmpty, Nested: jf.Nested}
+		if jf.Fields != nil {
+			if field.Fields, err = d.jsonFieldsOf(jf.Fields, goPrefix+jf.GoPath+"."); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, field)
+	}
+	return out, nil
+}
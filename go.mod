@@ -5,9 +5,12 @@ go 1.19
 require github.com/spf13/cast v1.5.0
 
 require (
+	github.com/mattn/go-sqlite3 v1.14.16
 	golang.org/x/exp v0.0.0-20220303212507-bbda1eaf7a17
+	google.golang.org/genproto v0.0.0-20221118155620-16455021b5e6
 	google.golang.org/grpc v1.52.0
 	google.golang.org/protobuf v1.28.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -15,5 +18,4 @@ require (
 	golang.org/x/net v0.4.0 // indirect
 	golang.org/x/sys v0.3.0 // indirect
 	golang.org/x/text v0.5.0 // indirect
-	google.golang.org/genproto v0.0.0-20221118155620-16455021b5e6 // indirect
 )
@@ -0,0 +1,20 @@
+// Package eface read the layout of an empty interface value
+// An interface{} is a pair of the address of its dynamic type's information and the address of its data,
+// the layout is internal to the go runtime, so it is kept here only and shared by search and fieldaccess
+
+package eface
+
+import (
+	"unsafe"
+)
+
+// Eface the layout of an empty interface value, the same as runtime.eface
+type Eface struct {
+	Typ   unsafe.Pointer // the type information of the dynamic type
+	Value unsafe.Pointer // the data, which is the pointer itself for a pointer type
+}
+
+// Of return the layout of data
+func Of(data interface{}) Eface {
+	return *(*Eface)(unsafe.Pointer(&data))
+}
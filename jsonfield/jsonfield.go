@@ -0,0 +1,167 @@
+// Package jsonfield list the fields of a struct type as encoding/json encodes them
+// json tags rename or skip fields, the fields of embedded structs without json tags are promoted,
+// and a name is won by the shallowest field, then by the only tagged one of the same depth,
+// otherwise the name is dropped.
+// Only reflect is used, so the package is safe for the search_safe build of package search
+
+package jsonfield
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimeType the type of time.Time, which is encoded as a string rather than walked through
+var TimeType = reflect.TypeOf(time.Time{})
+
+// Field a field encoded by encoding/json
+type Field struct {
+	Name      string       // json name
+	GoPath    string       // go names from the parent struct joined by dot, more than one if promoted
+	Index     []int        // indexes from the parent struct, more than one if promoted
+	Type      reflect.Type // type of the field
+	Tagged    bool         // has a json tag
+	OmitEmpty bool         // omitted by encoding/json when it is empty
+	Nested    bool         // a struct or a pointer of struct other than time.Time
+	Fields    []*Field     // the fields of a nested struct, nil if the type is recursive
+}
+
+// fieldsCache struct type -> []*Field
+var fieldsCache sync.Map
+
+// Of return the cached json fields of the struct type typ in the order of declaration
+func Of(typ reflect.Type) []*Field {
+	if fields, ok := fieldsCache.Load(typ); ok {
+		return fields.([]*Field)
+	}
+	fields, _ := fieldsCache.LoadOrStore(typ, fieldsOf(typ, map[reflect.Type]bool{}))
+	return fields.([]*Field)
+}
+
+// candidate a field which may win its json name, depth is the level of embedding
+type candidate struct {
+	order     int
+	name      string
+	goPath    string
+	index     []int
+	typ       reflect.Type
+	elem      reflect.Type // the struct type of a nested field
+	depth     int
+	tagged    bool
+	omitEmpty bool
+	nested    bool
+}
+
+// fieldsOf return the json fields of struct type typ, visiting breaks the cycles of pointers of struct
+func fieldsOf(typ reflect.Type, visiting map[reflect.Type]bool) []*Field {
+	visiting[typ] = true
+	defer delete(visiting, typ)
+	var cands []candidate
+	collect(typ, "", nil, 0, map[reflect.Type]bool{typ: true}, &cands)
+	winners := dominant(cands)
+	fields := make([]*Field, 0, len(winners))
+	for _, c := range winners {
+		f := &Field{
+			Name: c.name, GoPath: c.goPath, Index: c.index, Type: c.typ,
+			Tagged: c.tagged, OmitEmpty: c.omitEmpty, Nested: c.nested,
+		}
+		if c.nested && !visiting[c.elem] {
+			f.Fields = fieldsOf(c.elem, visiting)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// tag return the json name and options of the field, skip for json:"-"
+func tag(sf reflect.StructField) (name string, tagged, omitEmpty, skip bool) {
+	value, tagged := sf.Tag.Lookup("json")
+	if value == "-" {
+		return "", tagged, false, true
+	}
+	opts := strings.Split(value, ",")
+	name = opts[0]
+	if name == "" {
+		name = sf.Name
+	}
+	for _, opt := range opts[1:] {
+		omitEmpty = omitEmpty || opt == "omitempty"
+	}
+	return name, tagged, omitEmpty, false
+}
+
+// collect append the candidates of struct type typ, the fields of embedded structs without json tags
+// are promoted with depth+1, embedded breaks the cycles of embedded pointers
+func collect(
+	typ reflect.Type, goPrefix string, index []int, depth int, embedded map[reflect.Type]bool, out *[]candidate,
+) {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+		name, tagged, omitEmpty, skip := tag(sf)
+		if skip {
+			continue
+		}
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct {
+			ft = ft.Elem()
+		}
+		nested := ft.Kind() == reflect.Struct && ft != TimeType
+		fieldIndex := append(append(make([]int, 0, len(index)+1), index...), i)
+		if sf.Anonymous && !tagged && nested {
+			if !embedded[ft] {
+				embedded[ft] = true
+				collect(ft, goPrefix+sf.Name+".", fieldIndex, depth+1, embedded, out)
+				delete(embedded, ft)
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		*out = append(*out, candidate{
+			order: len(*out), name: name, goPath: goPrefix + sf.Name, index: fieldIndex, typ: sf.Type, elem: ft,
+			depth: depth, tagged: tagged, omitEmpty: omitEmpty, nested: nested,
+		})
+	}
+}
+
+// dominant return the candidates which win their names in the order of declaration
+func dominant(cands []candidate) []candidate {
+	byName := make(map[string][]candidate, len(cands))
+	for _, c := range cands {
+		byName[c.name] = append(byName[c.name], c)
+	}
+	out := make([]candidate, 0, len(byName))
+	for _, group := range byName {
+		minDepth := group[0].depth
+		for _, c := range group {
+			if c.depth < minDepth {
+				minDepth = c.depth
+			}
+		}
+		var shallowest, tagged []candidate
+		for _, c := range group {
+			if c.depth == minDepth {
+				shallowest = append(shallowest, c)
+				if c.tagged {
+					tagged = append(tagged, c)
+				}
+			}
+		}
+		if len(shallowest) == 1 {
+			out = append(out, shallowest[0])
+		} else if len(tagged) == 1 {
+			out = append(out, tagged[0])
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].order < out[j].order
+	})
+	return out
+}
@@ -0,0 +1,106 @@
+//go:build search_safe
+
+// Field access by reflect only
+// It is selected by the search_safe tag and does not depend on the layout of interface,
+// a field is read by its cached index like test/reflect_02_cache_test.go.
+// The results are the same as access_unsafe.go, but it is slower:
+//
+//	go test ./test -run xxx -bench 'SearchMode' [-tags search_safe]
+//
+//	benchmark                  unsafe                      search_safe
+//	SearchModeFilter           231 ns/op   1 allocs/op     831 ns/op   6 allocs/op
+//	SearchModeProject          1296 ns/op  5 allocs/op     1802 ns/op  5 allocs/op
+//	SearchModeDistinctRows     1269 ns/op  10 allocs/op    1307 ns/op  10 allocs/op
+//	SearchModeSort             1174 ns/op  4 allocs/op     1314 ns/op  4 allocs/op
+
+package search
+
+import (
+	"reflect"
+)
+
+// typeKey identify the dynamic type of an interface value
+type typeKey = reflect.Type
+
+// fieldRef refer to a field of a data
+type fieldRef = reflect.Value
+
+// typeOf the typeKey of data's dynamic type
+func typeOf(data interface{}) typeKey {
+	return reflect.TypeOf(data)
+}
+
+// fieldAt get the field in data by its index, data must be a pointer of struct
+func fieldAt(data interface{}, info *fieldInfo) fieldRef {
+	return reflect.ValueOf(data).Elem().Field(info.index)
+}
+
+// structField get the field of the addressable struct value v by its index, typ is the field's type
+func structField(v reflect.Value, info *fieldInfo, typ reflect.Type) reflect.Value {
+	return v.Field(info.index)
+}
+
+// boolValue get the value of the bool field
+func boolValue(ref fieldRef) bool {
+	return ref.Bool()
+}
+
+// intValue get the value of the signed integer field
+func intValue(ref fieldRef, kind reflect.Kind) int64 {
+	return ref.Int()
+}
+
+// uintValue get the value of the unsigned integer field
+func uintValue(ref fieldRef, kind reflect.Kind) uint64 {
+	return ref.Uint()
+}
+
+// floatValue get the value of the float field
+func floatValue(ref fieldRef, kind reflect.Kind) float64 {
+	return ref.Float()
+}
+
+// stringValue get the value of the string field
+func stringValue(ref fieldRef) string {
+	return ref.String()
+}
+
+// match Check whether the data meets the search condition, the field is read by reflect
+func (s *Searcher) match(in interface{}) bool {
+	return s.matchTyped(fieldValue(fieldAt(in, s.fieldInfo), s.fieldKind))
+}
+
+// setValue set the field to value whose type is the field's kind, return whether the field is changed
+func setValue(ref fieldRef, kind reflect.Kind, value interface{}) bool {
+	v := reflect.ValueOf(value)
+	switch {
+	case kind == reflect.Bool:
+		if ref.Bool() == v.Bool() {
+			return false
+		}
+		ref.SetBool(v.Bool())
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		if ref.Int() == v.Int() {
+			return false
+		}
+		ref.SetInt(v.Int())
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		if ref.Uint() == v.Uint() {
+			return false
+		}
+		ref.SetUint(v.Uint())
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		if ref.Float() == v.Float() {
+			return false
+		}
+		ref.SetFloat(v.Float())
+	case kind == reflect.String:
+		if ref.String() == v.String() {
+			return false
+		}
+		ref.SetString(v.String())
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,178 @@
+//go:build !search_safe
+
+// Field access by offsets
+// The data's type and address are read from the layout of interface{}, see package eface,
+// and a field is read at the address plus its offset, which avoids the cost of reflect.
+// Build with the search_safe tag to access fields by reflect only, see access_safe.go
+
+package search
+
+import (
+	"go_tests/internal/eface"
+	"reflect"
+	"regexp"
+	"unsafe"
+)
+
+// typeKey identify the dynamic type of an interface value,
+// the address of type information is compared directly without hashing reflect.Type
+type typeKey = unsafe.Pointer
+
+// fieldRef refer to a field of a data
+type fieldRef = unsafe.Pointer
+
+// typeOf the typeKey of data's dynamic type
+func typeOf(data interface{}) typeKey {
+	return eface.Of(data).Typ
+}
+
+// fieldAt get the address of the field in data, data must be a pointer of struct
+func fieldAt(data interface{}, info *fieldInfo) fieldRef {
+	return unsafe.Add(eface.Of(data).Value, info.offset)
+}
+
+// structField get the field of the addressable struct value v at its offset, typ is the field's type
+func structField(v reflect.Value, info *fieldInfo, typ reflect.Type) reflect.Value {
+	return reflect.NewAt(typ, unsafe.Add(unsafe.Pointer(v.UnsafeAddr()), info.offset)).Elem()
+}
+
+// boolValue get the value of the bool field
+func boolValue(ref fieldRef) bool {
+	return *(*bool)(ref)
+}
+
+// intValue get the value of the signed integer field
+func intValue(ref fieldRef, kind reflect.Kind) int64 {
+	switch kind {
+	case reflect.Int:
+		return int64(*(*int)(ref))
+	case reflect.Int8:
+		return int64(*(*int8)(ref))
+	case reflect.Int16:
+		return int64(*(*int16)(ref))
+	case reflect.Int32:
+		return int64(*(*int32)(ref))
+	}
+	return *(*int64)(ref)
+}
+
+// uintValue get the value of the unsigned integer field
+func uintValue(ref fieldRef, kind reflect.Kind) uint64 {
+	switch kind {
+	case reflect.Uint:
+		return uint64(*(*uint)(ref))
+	case reflect.Uint8:
+		return uint64(*(*uint8)(ref))
+	case reflect.Uint16:
+		return uint64(*(*uint16)(ref))
+	case reflect.Uint32:
+		return uint64(*(*uint32)(ref))
+	}
+	return *(*uint64)(ref)
+}
+
+// floatValue get the value of the float field
+func floatValue(ref fieldRef, kind reflect.Kind) float64 {
+	if kind == reflect.Float32 {
+		return float64(*(*float32)(ref))
+	}
+	return *(*float64)(ref)
+}
+
+// stringValue get the value of the string field
+func stringValue(ref fieldRef) string {
+	return *(*string)(ref)
+}
+
+// match Check whether the data meets the search condition, the field is read by its offset
+func (s *Searcher) match(in interface{}) bool {
+	dataPtr := fieldAt(in, s.fieldInfo)
+	if s.SearchOperator == SEARCH_OPERATOR_IN {
+		v := fieldValue(dataPtr, s.fieldKind)
+		for _, value := range s.value.([]interface{}) {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}
+	if s.SearchOperator == SEARCH_OPERATOR_MATCH {
+		return matchTerms(s.tokenizer.Tokenize(*(*string)(dataPtr)), s.value.([]string))
+	}
+	if s.SearchOperator == SEARCH_OPERATOR_REGEX {
+		return s.value.(*regexp.Regexp).MatchString(*(*string)(dataPtr))
+	}
+	switch s.fieldKind {
+	case reflect.Int:
+		return doNumbericMatch(*(*int)(dataPtr), s.value.(int), s.SearchOperator)
+	case reflect.Int8:
+		return doNumbericMatch(*(*int8)(dataPtr), s.value.(int8), s.SearchOperator)
+	case reflect.Int16:
+		return doNumbericMatch(*(*int16)(dataPtr), s.value.(int16), s.SearchOperator)
+	case reflect.Int32:
+		return doNumbericMatch(*(*int32)(dataPtr), s.value.(int32), s.SearchOperator)
+	case reflect.Int64:
+		return doNumbericMatch(*(*int64)(dataPtr), s.value.(int64), s.SearchOperator)
+	case reflect.Uint:
+		return doNumbericMatch(*(*uint)(dataPtr), s.value.(uint), s.SearchOperator)
+	case reflect.Uint8:
+		return doNumbericMatch(*(*uint8)(dataPtr), s.value.(uint8), s.SearchOperator)
+	case reflect.Uint16:
+		return doNumbericMatch(*(*uint16)(dataPtr), s.value.(uint16), s.SearchOperator)
+	case reflect.Uint32:
+		return doNumbericMatch(*(*uint32)(dataPtr), s.value.(uint32), s.SearchOperator)
+	case reflect.Uint64:
+		return doNumbericMatch(*(*uint64)(dataPtr), s.value.(uint64), s.SearchOperator)
+	case reflect.Float32:
+		return doNumbericMatch(*(*float32)(dataPtr), s.value.(float32), s.SearchOperator)
+	case reflect.Float64:
+		return doNumbericMatch(*(*float64)(dataPtr), s.value.(float64), s.SearchOperator)
+	case reflect.String:
+		return doStringMatch(*(*string)(dataPtr), s.value.(string), s.SearchOperator)
+	}
+	return false
+}
+
+// setValue set the field to value whose type is the field's kind, return whether the field is changed
+func setValue(ref fieldRef, kind reflect.Kind, value interface{}) bool {
+	switch kind {
+	case reflect.Bool:
+		return setIfChanged((*bool)(ref), value.(bool))
+	case reflect.Int:
+		return setIfChanged((*int)(ref), value.(int))
+	case reflect.Int8:
+		return setIfChanged((*int8)(ref), value.(int8))
+	case reflect.Int16:
+		return setIfChanged((*int16)(ref), value.(int16))
+	case reflect.Int32:
+		return setIfChanged((*int32)(ref), value.(int32))
+	case reflect.Int64:
+		return setIfChanged((*int64)(ref), value.(int64))
+	case reflect.Uint:
+		return setIfChanged((*uint)(ref), value.(uint))
+	case reflect.Uint8:
+		return setIfChanged((*uint8)(ref), value.(uint8))
+	case reflect.Uint16:
+		return setIfChanged((*uint16)(ref), value.(uint16))
+	case reflect.Uint32:
+		return setIfChanged((*uint32)(ref), value.(uint32))
+	case reflect.Uint64:
+		return setIfChanged((*uint64)(ref), value.(uint64))
+	case reflect.Float32:
+		return setIfChanged((*float32)(ref), value.(float32))
+	case reflect.Float64:
+		return setIfChanged((*float64)(ref), value.(float64))
+	case reflect.String:
+		return setIfChanged((*string)(ref), value.(string))
+	}
+	return false
+}
+
+// setIfChanged set *p to v if they are different
+func setIfChanged[T comparable](p *T, v T) bool {
+	if *p == v {
+		return false
+	}
+	*p = v
+	return true
+}
@@ -0,0 +1,325 @@
+// Indexed collection for structures registered by a SearcherLimit
+// Query uses a secondary index when one of the searchers allows it
+// and falls back to scanning all datas otherwise
+
+package search
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// collectionIndex a secondary index of one field
+type collectionIndex struct {
+	field    string
+	info     *fieldInfo
+	hash     *hashIndex     // not nil for hash index
+	ordered  *orderedIndex  // not nil for ordered index
+	fulltext *invertedIndex // not nil for full-text index
+}
+
+// indexType name of the index type
+func (ci *collectionIndex) indexType() string {
+	if ci.hash != nil {
+		return "hash"
+	}
+	if ci.ordered != nil {
+		return "ordered"
+	}
+	return "full-text"
+}
+
+func (ci *collectionIndex) insert(key interface{}, id uint64) {
+	if ci.hash != nil {
+		ci.hash.insert(key, id)
+		return
+	}
+	if ci.ordered != nil {
+		ci.ordered.insert(key, id)
+		return
+	}
+	ci.fulltext.insert(key.(string), id)
+}
+
+func (ci *collectionIndex) remove(key interface{}, id uint64) {
+	if ci.hash != nil {
+		ci.hash.remove(key, id)
+		return
+	}
+	if ci.ordered != nil {
+		ci.ordered.remove(key, id)
+		return
+	}
+	ci.fulltext.remove(key.(string), id)
+}
+
+// collectionRow a data and its keys in every index
+type collectionRow struct {
+	data interface{}
+	keys []interface{} // keys[k] is the key of data in Collection.indexes[k]
+}
+
+// Collection an in-memory collection with secondary indexes, safe for concurrent use
+type Collection struct {
+	mu      sync.RWMutex
+	limit   *SearcherLimit
+	rows    []*collectionRow // row id is the index, deleted row is nil
+	count   int
+	indexes []*collectionIndex
+}
+
+// NewCollection Construct an empty collection for the struct type of limit
+func NewCollection(limit *SearcherLimit) *Collection {
+	return &Collection{limit: limit}
+}
+
+// AddHashIndex add a hash index for field, it is used by eq/in
+func (c *Collection) AddHashIndex(field string) error {
+	return c.addIndex(field, &collectionIndex{field: field, hash: newHashIndex()})
+}
+
+// AddOrderedIndex add an ordered index for field, it is used by eq/lt/lte/gt/gte
+func (c *Collection) AddOrderedIndex(field string) error {
+	return c.addIndex(field, &collectionIndex{field: field, ordered: newOrderedIndex()})
+}
+
+func (c *Collection) addIndex(field string, ci *collectionIndex) error {
+	infos, err := c.limit.getFieldInfos([]string{field}, "index")
+	if err != nil {
+		return err
+	}
+	ci.info = infos[0]
+	if !(ci.info.kind >= reflect.Int && ci.info.kind <= reflect.Float64) && ci.info.kind != reflect.String {
+		return fmt.Errorf("field(%s) is not number or string type, can not be indexed", field)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, index := range c.indexes {
+		if index.field == field && index.indexType() == ci.indexType() {
+			return fmt.Errorf("field(%s) is already indexed", field)
+		}
+	}
+	for id, row := range c.rows {
+		if row == nil {
+			continue
+		}
+		key := fieldValue(fieldAt(row.data, ci.info), ci.info.kind)
+		ci.insert(key, uint64(id))
+		row.keys = append(row.keys, key)
+	}
+	c.indexes = append(c.indexes, ci)
+	return nil
+}
+
+// Len return the number of datas in the collection
+func (c *Collection) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.count
+}
+
+// Get return the data of id
+func (c *Collection) Get(id uint64) (data interface{}, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if id >= uint64(len(c.rows)) || c.rows[id] == nil {
+		return nil, false
+	}
+	return c.rows[id].data, true
+}
+
+// Insert add data to the collection and return its id
+func (c *Collection) Insert(data interface{}) (id uint64, err error) {
+	if err = c.limit.checkParam(data); err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id = uint64(len(c.rows))
+	row := &collectionRow{data: data}
+	c.indexRow(id, row)
+	c.rows = append(c.rows, row)
+	c.count++
+	return id, nil
+}
+
+// Update replace the data of id and maintain the indexes,
+// data can be the same pointer which has been modified in place
+func (c *Collection) Update(id uint64, data interface{}) error {
+	if err := c.limit.checkParam(data); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if id >= uint64(len(c.rows)) || c.rows[id] == nil {
+		return fmt.Errorf("row(%d) does not exist", id)
+	}
+	c.unindexRow(id, c.rows[id])
+	row := &collectionRow{data: data}
+	c.indexRow(id, row)
+	c.rows[id] = row
+	return nil
+}
+
+// Delete remove the data of id
+func (c *Collection) Delete(id uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if id >= uint64(len(c.rows)) || c.rows[id] == nil {
+		return fmt.Errorf("row(%d) does not exist", id)
+	}
+	c.unindexRow(id, c.rows[id])
+	c.rows[id] = nil
+	c.count--
+	return nil
+}
+
+func (c *Collection) indexRow(id uint64, row *collectionRow) {
+	row.keys = make([]interface{}, len(c.indexes))
+	for k, index := range c.indexes {
+		row.keys[k] = fieldValue(fieldAt(row.data, index.info), index.info.kind)
+		index.insert(row.keys[k], id)
+	}
+}
+
+func (c *Collection) unindexRow(id uint64, row *collectionRow) {
+	for k, index := range c.indexes {
+		index.remove(row.keys[k], id)
+	}
+}
+
+// plan choose the index and the searcher used to find the candidate rows,
+// hash index is preferred, then full-text index and ordered index,
+// nil index means scanning all datas
+func (c *Collection) plan(searchers []*Searcher) (*collectionIndex, *Searcher) {
+	var ordered, fulltext *collectionIndex
+	var orderedSearcher, fulltextSearcher *Searcher
+	for _, s := range searchers {
+		for _, index := range c.indexes {
+			if index.field != s.Field {
+				continue
+			}
+			switch s.SearchOperator {
+			case SEARCH_OPERATOR_EQUAL, SEARCH_OPERATOR_IN:
+				if index.hash != nil {
+					return index, s
+				}
+				if s.SearchOperator == SEARCH_OPERATOR_EQUAL && ordered == nil {
+					ordered, orderedSearcher = index, s
+				}
+			case SEARCH_OPERATOR_LESS, SEARCH_OPERATOR_LESS_EQUAL,
+				SEARCH_OPERATOR_GREATER, SEARCH_OPERATOR_GREATER_EQUAL:
+				if index.ordered != nil && ordered == nil {
+					ordered, orderedSearcher = index, s
+				}
+			case SEARCH_OPERATOR_MATCH:
+				if index.fulltext != nil && fulltext == nil {
+					fulltext, fulltextSearcher = index, s
+				}
+			}
+		}
+	}
+	if fulltext != nil {
+		return fulltext, fulltextSearcher
+	}
+	return ordered, orderedSearcher
+}
+
+// candidates return the ids found by index for searcher in ascending order
+func (c *Collection) candidates(index *collectionIndex, s *Searcher) []uint64 {
+	var ids []uint64
+	add := func(id uint64) { ids = append(ids, id) }
+	if index.hash != nil {
+		if s.SearchOperator != SEARCH_OPERATOR_IN {
+			index.hash.lookup(s.value, add)
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			return ids
+		}
+		looked := make(map[interface{}]bool)
+		for _, value := range s.value.([]interface{}) {
+			if !looked[value] { // duplicate values in the list
+				looked[value] = true
+				index.hash.lookup(value, add)
+			}
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		return ids
+	}
+	if index.fulltext != nil {
+		index.fulltext.lookup(s.value.([]string), add)
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		return ids
+	}
+	switch s.SearchOperator {
+	case SEARCH_OPERATOR_EQUAL:
+		index.ordered.scan(s.value, true, s.value, true, add)
+	case SEARCH_OPERATOR_LESS:
+		index.ordered.scan(nil, false, s.value, false, add)
+	case SEARCH_OPERATOR_LESS_EQUAL:
+		index.ordered.scan(nil, false, s.value, true, add)
+	case SEARCH_OPERATOR_GREATER:
+		index.ordered.scan(s.value, false, nil, false, add)
+	case SEARCH_OPERATOR_GREATER_EQUAL:
+		index.ordered.scan(s.value, true, nil, false, add)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Explain describe how Query will find the datas of searchers
+func (c *Collection) Explain(searchers []*Searcher) (string, error) {
+	searchers, err := c.limit.compile(searchers)
+	if err != nil {
+		return "", err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	index, s := c.plan(searchers)
+	if index == nil {
+		return "scan", nil
+	}
+	return fmt.Sprintf("%s index(%s) %s", index.indexType(), s.Field, searchOperatorName[s.SearchOperator]), nil
+}
+
+// Query return the datas which meet all the searchers in insertion order
+func (c *Collection) Query(searchers []*Searcher) (datasOut []interface{}, err error) {
+	if searchers, err = c.limit.compile(searchers); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	datasOut, _ = c.query(searchers)
+	return datasOut, nil
+}
+
+// query return the datas which meet all the valid searchers and their ids,
+// the caller must hold the read lock
+func (c *Collection) query(searchers []*Searcher) (datasOut []interface{}, ids []uint64) {
+	matchAll := func(data interface{}, skip *Searcher) bool {
+		for _, s := range searchers {
+			if s != skip && !s.match(data) {
+				return false
+			}
+		}
+		return true
+	}
+	index, used := c.plan(searchers)
+	if index == nil {
+		for id, row := range c.rows {
+			if row != nil && matchAll(row.data, nil) {
+				datasOut = append(datasOut, row.data)
+				ids = append(ids, uint64(id))
+			}
+		}
+		return datasOut, ids
+	}
+	for _, id := range c.candidates(index, used) {
+		if data := c.rows[id].data; matchAll(data, used) {
+			datasOut = append(datasOut, data)
+			ids = append(ids, id)
+		}
+	}
+	return datasOut, ids
+}
@@ -0,0 +1,87 @@
+// Compiled query
+// ValidCheck stores the compiled state(kind, offset, value) in the caller's searchers,
+// so sharing searchers between goroutines or limits races.
+// Compile checks copies of the searchers instead, and the returned Query is never modified afterwards.
+// FilterJSON, FilterMaps, Rank, Collection.Query and the other APIs taking searchers compile copies as well,
+// they do not fill the compiled state into the caller's searchers any more, so a searcher passed to them
+// must still be checked by ValidCheck before Searcher.Filter is called with it
+
+package search
+
+import (
+	"fmt"
+)
+
+// compile check the copies of searchers and return the copies with compiled state,
+// the searchers themselves are not modified
+func (s *SearcherLimit) compile(searchers []*Searcher) ([]*Searcher, error) {
+	compiled := make([]*Searcher, len(searchers))
+	for k, searcher := range searchers {
+		if searcher == nil {
+			return nil, fmt.Errorf("searchers[%d] is nil", k)
+		}
+		c := &Searcher{Field: searcher.Field, SearchOperator: searcher.SearchOperator, Value: searcher.Value}
+		if err := s.validCheck(k, c); err != nil {
+			return nil, err
+		}
+		compiled[k] = c
+	}
+	return compiled, nil
+}
+
+// Query the searchers compiled for a SearcherLimit, it is immutable and safe for concurrent use
+type Query struct {
+	limit     *SearcherLimit
+	searchers []*Searcher // compiled copies, never modified
+}
+
+// Compile check the searchers and compile them into a Query of the limit,
+// the searchers are not modified, so they can be shared and compiled for other limits
+func (s *SearcherLimit) Compile(searchers []*Searcher) (*Query, error) {
+	compiled, err := s.compile(searchers)
+	if err != nil {
+		return nil, err
+	}
+	return &Query{limit: s, searchers: compiled}, nil
+}
+
+// Limit the limit which the query is compiled for
+func (q *Query) Limit() *SearcherLimit {
+	return q.limit
+}
+
+// Match check whether data meets all the searchers, data must be a pointer of the limit's struct type
+func (q *Query) Match(data interface{}) (bool, error) {
+	if err := q.limit.checkParam(data); err != nil {
+		return false, err
+	}
+	return matchAll(q.searchers, data), nil
+}
+
+// MatchMap check whether the row meets all the searchers, the searchers are compiled by Compile
+// and not modified, invalid searchers match no row. Compile once and use Query.MatchMap for many rows
+func (s *SearcherLimit) MatchMap(searchers []*Searcher, row map[string]interface{}) bool {
+	query, err := s.Compile(searchers)
+	if err != nil {
+		return false
+	}
+	return query.MatchMap(row)
+}
+
+// MatchMap check whether the map row meets all the searchers
+func (q *Query) MatchMap(row map[string]interface{}) bool {
+	return matchRow(q.searchers, row)
+}
+
+// Filter return the datas which meet all the searchers in input order
+func (q *Query) Filter(datasIn []interface{}) (datasOut []interface{}, err error) {
+	for i, data := range datasIn {
+		if err = q.limit.checkData(data, i); err != nil {
+			return nil, err
+		}
+		if matchAll(q.searchers, data) {
+			datasOut = append(datasOut, data)
+		}
+	}
+	return datasOut, nil
+}
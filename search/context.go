@@ -0,0 +1,70 @@
+// Context-aware validity check and filter
+// They stop when ctx is done, e.g. the client of a grpc handler goes away or the deadline is exceeded
+
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/status"
+)
+
+// contextCheckInterval the number of datas filtered between two checks of ctx
+const contextCheckInterval = 1024
+
+// ProgressError the error returned when ctx is done before the filter finishes,
+// Err is ctx.Err(), Processed datas of Total datas have been filtered
+type ProgressError struct {
+	Err       error
+	Processed int
+	Total     int
+}
+
+func (e *ProgressError) Error() string {
+	return fmt.Sprintf("filter stopped after %d/%d datas: %s", e.Processed, e.Total, e.Err.Error())
+}
+
+// Unwrap return ctx.Err(), so errors.Is(err, context.Canceled) works
+func (e *ProgressError) Unwrap() error {
+	return e.Err
+}
+
+// GRPCStatus the status returned by a grpc handler, codes.Canceled or codes.DeadlineExceeded
+func (e *ProgressError) GRPCStatus() *status.Status {
+	return status.New(status.FromContextError(e.Err).Code(), e.Error())
+}
+
+// ValidCheckContext the same as ValidCheck, but it returns ctx.Err() when ctx is done
+func (s *SearcherLimit) ValidCheckContext(ctx context.Context, infos []*Searcher) error {
+	for k, info := range infos {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.validCheck(k, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FilterContext the same as Filter, but it checks ctx periodically,
+// when ctx is done the datas matched so far are returned with a *ProgressError
+func (s *Searcher) FilterContext(
+	ctx context.Context, limit *SearcherLimit, datasIn []interface{},
+) (datasOut []interface{}, err error) {
+	for i := 0; i < len(datasIn); i++ {
+		if i%contextCheckInterval == 0 {
+			if err = ctx.Err(); err != nil {
+				return datasOut, &ProgressError{Err: err, Processed: i, Total: len(datasIn)}
+			}
+		}
+		if err = limit.checkData(datasIn[i], i); err != nil {
+			return nil, err
+		}
+		if s.match(datasIn[i]) {
+			datasOut = append(datasOut, datasIn[i])
+		}
+	}
+	return datasOut, nil
+}
@@ -0,0 +1,267 @@
+// Struct diff
+// The json tagged fields of two versions of a struct are located by fieldInfo like the searched fields,
+// nested structs are walked through and the other fields are compared as a whole,
+// the changes can be written as a JSON Patch(RFC 6902) against the json of the old version
+
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"go_tests/jsonfield"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JSON Patch operations
+const (
+	PATCH_OP_ADD     = "add"
+	PATCH_OP_REMOVE  = "remove"
+	PATCH_OP_REPLACE = "replace"
+)
+
+// Change a changed field
+type Change struct {
+	Field string      // json names joined by dot, e.g. inner.name
+	Path  string      // JSON Pointer of the field, e.g. /inner/name
+	Op    string      // the JSON Patch operation, add/remove for the fields which are omitted from one version
+	Old   interface{} // value in the old version
+	New   interface{} // value in the new version
+}
+
+// Changes the changed fields in the order of declaration
+type Changes []*Change
+
+// PatchOperation an operation of JSON Patch
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON omit the value of remove only, a zero or null value is kept for add and replace
+func (p *PatchOperation) MarshalJSON() ([]byte, error) {
+	if p.Op == PATCH_OP_REMOVE {
+		return json.Marshal(map[string]string{"op": p.Op, "path": p.Path})
+	}
+	type operation PatchOperation
+	return json.Marshal((*operation)(p))
+}
+
+// Patch return the JSON Patch operations which change the old version to the new version
+func (c Changes) Patch() []*PatchOperation {
+	ops := make([]*PatchOperation, len(c))
+	for k, change := range c {
+		op := &PatchOperation{Op: change.Op, Path: change.Path}
+		if change.Op != PATCH_OP_REMOVE {
+			op.Value = change.New
+		}
+		ops[k] = op
+	}
+	return ops
+}
+
+// JSONPatch return the JSON Patch document of Patch
+func (c Changes) JSONPatch() ([]byte, error) {
+	return json.Marshal(c.Patch())
+}
+
+// diffStep a field on the path from a struct to a compared field, more than one if the field is promoted
+type diffStep struct {
+	info *fieldInfo
+	typ  reflect.Type
+}
+
+// diffField a compared field located by the same fieldInfo as the searched fields
+type diffField struct {
+	*jsonfield.Field
+	steps  []diffStep
+	fields []*diffField // the fields of a nested struct, nil if the type is recursive
+}
+
+// diffPlans reflect.Type of struct -> []*diffField
+var diffPlans sync.Map
+
+// diffPlanOf return the cached compared fields of the struct type
+func diffPlanOf(typ reflect.Type) []*diffField {
+	if plan, ok := diffPlans.Load(typ); ok {
+		return plan.([]*diffField)
+	}
+	plan, _ := diffPlans.LoadOrStore(typ, newDiffFields(typ, jsonfield.Of(typ)))
+	return plan.([]*diffField)
+}
+
+// newDiffFields locate the json fields of the struct type typ,
+// the fields promoted through unexported embedded structs are skipped since reflect can not read them
+func newDiffFields(typ reflect.Type, fields []*jsonfield.Field) []*diffField {
+	plan := make([]*diffField, 0, len(fields))
+	for _, f := range fields {
+		df := &diffField{Field: f, steps: make([]diffStep, len(f.Index))}
+		st, readable := typ, true
+		for k, i := range f.Index {
+			if st.Kind() == reflect.Ptr {
+				st = st.Elem()
+			}
+			sf := st.Field(i)
+			readable = readable && sf.IsExported()
+			df.steps[k] = diffStep{info: &fieldInfo{index: i, offset: sf.Offset, kind: sf.Type.Kind()}, typ: sf.Type}
+			st = sf.Type
+		}
+		if !readable {
+			continue
+		}
+		if f.Fields != nil {
+			elem := f.Type
+			if elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			df.fields = newDiffFields(elem, f.Fields)
+		}
+		plan = append(plan, df)
+	}
+	return plan
+}
+
+// locate return the field of the addressable struct value v, or the invalid value
+// if v is invalid or a pointer on the path is nil, so the field is absent from the json
+func (f *diffField) locate(v reflect.Value) reflect.Value {
+	for _, step := range f.steps {
+		if !v.IsValid() {
+			return v
+		}
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = structField(v, step.info, step.typ)
+	}
+	return v
+}
+
+// Diff compare the json tagged fields of old and new, which are structs or non-nil pointers of the same struct type,
+// the fields are listed by jsonfield.Of and read by the same access as the searched fields,
+// so the embedded structs without json tags are promoted like encoding/json,
+// the fields promoted through a nil embedded pointer are added or removed as a whole
+// and the fields promoted through unexported embedded structs are not compared
+func Diff(old, new interface{}) (Changes, error) {
+	if old == nil || new == nil {
+		return nil, errors.New("param old or new is nil")
+	}
+	oldV, newV := reflect.ValueOf(old), reflect.ValueOf(new)
+	if oldV.Type() != newV.Type() {
+		return nil, errors.New("param old and new are not the same type")
+	}
+	if oldV.Kind() == reflect.Ptr {
+		if oldV.IsNil() || newV.IsNil() {
+			return nil, errors.New("param old or new is a nil pointer")
+		}
+		oldV, newV = oldV.Elem(), newV.Elem()
+	} else {
+		// the fields are accessed by address
+		oldV, newV = addressable(oldV), addressable(newV)
+	}
+	if oldV.Kind() != reflect.Struct {
+		return nil, errors.New("param old and new must be structs or pointers of struct")
+	}
+	var changes Changes
+	diffFields(oldV, newV, diffPlanOf(oldV.Type()), "", "", &changes)
+	return changes, nil
+}
+
+// addressable return an addressable copy of v
+func addressable(v reflect.Value) reflect.Value {
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	return ptr.Elem()
+}
+
+// diffFields append the changes of the fields of the struct values, field and path are the prefixes of nested fields,
+// a struct value is invalid when it is absent from the json
+func diffFields(oldV, newV reflect.Value, fields []*diffField, field, path string, changes *Changes) {
+	for _, df := range fields {
+		if !df.Tagged {
+			continue
+		}
+		o, n := df.locate(oldV), df.locate(newV)
+		if !o.IsValid() && !n.IsValid() {
+			continue
+		}
+		name, pointer := field+df.Name, path+"/"+escapePointer(df.Name)
+		present := o.IsValid() && n.IsValid()
+		if present && df.fields != nil && (o.Kind() == reflect.Struct || (!o.IsNil() && !n.IsNil())) {
+			diffFields(o, n, df.fields, name+".", pointer, changes)
+			continue
+		}
+		if present && equalValue(o, n) {
+			continue
+		}
+		// a field omitted from the json is absent like the fields of a nil embedded pointer
+		oEmpty, nEmpty := !o.IsValid() || df.OmitEmpty && isEmptyValue(o), !n.IsValid() || df.OmitEmpty && isEmptyValue(n)
+		if oEmpty && nEmpty {
+			continue
+		}
+		change := &Change{Field: name, Path: pointer, Op: PATCH_OP_REPLACE}
+		if o.IsValid() {
+			change.Old = o.Interface()
+		}
+		if n.IsValid() {
+			change.New = n.Interface()
+		}
+		if oEmpty {
+			change.Op = PATCH_OP_ADD
+		} else if nEmpty {
+			change.Op = PATCH_OP_REMOVE
+		}
+		*changes = append(*changes, change)
+	}
+}
+
+// equalValue compare the values of a field, time.Time is compared by Equal and NaN equals NaN,
+// == is used for the basic kinds only, since a comparable type may hold uncomparable values in interfaces
+func equalValue(o, n reflect.Value) bool {
+	if o.Type() == jsonfield.TimeType {
+		return o.Interface().(time.Time).Equal(n.Interface().(time.Time))
+	}
+	switch o.Kind() {
+	case reflect.Bool:
+		return o.Bool() == n.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return o.Int() == n.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return o.Uint() == n.Uint()
+	case reflect.Float32, reflect.Float64:
+		return o.Float() == n.Float() || math.IsNaN(o.Float()) && math.IsNaN(n.Float())
+	case reflect.String:
+		return o.String() == n.String()
+	}
+	return reflect.DeepEqual(o.Interface(), n.Interface())
+}
+
+// isEmptyValue whether encoding/json omits the value with omitempty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// escapePointer escape a reference token of JSON Pointer(RFC 6901)
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,89 @@
+// Distinct values and rows deduplication
+// The chosen fields are read by their cached information
+// and hashed together as the key, the first seen data is kept
+
+package search
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+)
+
+// appendFieldKey append the value of the field referred by ref to dst as a hash key
+func appendFieldKey(dst []byte, ref fieldRef, kind reflect.Kind) []byte {
+	switch kind {
+	case reflect.Bool:
+		if boolValue(ref) {
+			return append(dst, 1)
+		}
+		return append(dst, 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.LittleEndian.AppendUint64(dst, uint64(intValue(ref, kind)))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return binary.LittleEndian.AppendUint64(dst, uintValue(ref, kind))
+	case reflect.Float32:
+		return binary.LittleEndian.AppendUint32(dst, math.Float32bits(float32(floatValue(ref, kind))))
+	case reflect.Float64:
+		return binary.LittleEndian.AppendUint64(dst, math.Float64bits(floatValue(ref, kind)))
+	case reflect.String:
+		str := stringValue(ref)
+		dst = binary.AppendUvarint(dst, uint64(len(str))) // length prefix keeps composite keys unambiguous
+		return append(dst, str...)
+	}
+	return dst
+}
+
+// DistinctValues return the unique values of field among datasIn in first-seen order
+func (s *SearcherLimit) DistinctValues(
+	field string, datasIn []interface{},
+) (values []interface{}, err error) {
+	infos, err := s.getFieldInfos([]string{field}, "distinct")
+	if err != nil {
+		return nil, err
+	}
+	info := infos[0]
+	seen := make(map[string]struct{})
+	var key []byte
+	for i, data := range datasIn {
+		if err = s.checkData(data, i); err != nil {
+			return nil, err
+		}
+		ref := fieldAt(data, info)
+		key = appendFieldKey(key[:0], ref, info.kind)
+		if _, ok := seen[string(key)]; ok {
+			continue
+		}
+		seen[string(key)] = struct{}{}
+		values = append(values, fieldValue(ref, info.kind))
+	}
+	return values, nil
+}
+
+// DistinctRows deduplicate datasIn by the composite key of fields,
+// only the first data of each key is kept and the input order is preserved
+func (s *SearcherLimit) DistinctRows(
+	fields []string, datasIn []interface{},
+) (datasOut []interface{}, err error) {
+	infos, err := s.getFieldInfos(fields, "distinct")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	var key []byte
+	for i, data := range datasIn {
+		if err = s.checkData(data, i); err != nil {
+			return nil, err
+		}
+		key = key[:0]
+		for _, info := range infos {
+			key = appendFieldKey(key, fieldAt(data, info), info.kind)
+		}
+		if _, ok := seen[string(key)]; ok {
+			continue
+		}
+		seen[string(key)] = struct{}{}
+		datasOut = append(datasOut, data)
+	}
+	return datasOut, nil
+}
@@ -0,0 +1,252 @@
+// Translate searchers into an Elasticsearch bool query
+// The json names are used as the field names of documents
+
+package search
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// escapeWildcard escape the special characters of elasticsearch wildcard query
+func escapeWildcard(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`).Replace(value)
+}
+
+// luceneReserved the characters escaped in lucene regexp, including the operators enabled by the default flags ALL
+const luceneReserved = `.?+*|{}[]()"\#@&<>~^-`
+
+// luceneRegexp translate a go regexp into lucene regexp, which is always anchored and has no \s, \d or ^/$.
+// The pattern is parsed by regexp/syntax, so the classes are expanded to ranges,
+// the unanchored ends are padded by .* and the anchors are removed,
+// an error is returned for the constructs lucene does not support, such as \b and multi-line anchors
+func luceneRegexp(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	branches := []*syntax.Regexp{re}
+	if re.Op == syntax.OpAlternate {
+		branches = re.Sub
+	}
+	var b strings.Builder
+	for i, branch := range branches {
+		if i > 0 {
+			b.WriteByte('|')
+		}
+		subs := []*syntax.Regexp{branch}
+		if branch.Op == syntax.OpConcat {
+			subs = branch.Sub
+		}
+		if len(subs) > 0 && subs[0].Op == syntax.OpBeginText {
+			subs = subs[1:]
+		} else {
+			b.WriteString(".*")
+		}
+		anchored := len(subs) > 0 && subs[len(subs)-1].Op == syntax.OpEndText
+		if anchored {
+			subs = subs[:len(subs)-1]
+		}
+		for _, sub := range subs {
+			if err = writeLucene(&b, sub, true); err != nil {
+				return "", err
+			}
+		}
+		if !anchored {
+			b.WriteString(".*")
+		}
+	}
+	return b.String(), nil
+}
+
+// writeLucene write re in lucene regexp syntax, grouped wraps alternations in parentheses
+func writeLucene(b *strings.Builder, re *syntax.Regexp, grouped bool) error {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		b.WriteString("()")
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && unicode.ToUpper(r) != unicode.ToLower(r) {
+				b.WriteByte('[')
+				writeLuceneRune(b, unicode.ToLower(r))
+				writeLuceneRune(b, unicode.ToUpper(r))
+				b.WriteByte(']')
+				continue
+			}
+			writeLuceneRune(b, r)
+		}
+	case syntax.OpCharClass:
+		writeLuceneClass(b, re.Rune)
+	case syntax.OpAnyChar:
+		b.WriteByte('.')
+	case syntax.OpAnyCharNotNL:
+		b.WriteString("[^\n]")
+	case syntax.OpCapture:
+		b.WriteByte('(')
+		if err := writeLucene(b, re.Sub[0], false); err != nil {
+			return err
+		}
+		b.WriteByte(')')
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		if err := writeLuceneAtom(b, re.Sub[0]); err != nil {
+			return err
+		}
+		switch re.Op {
+		case syntax.OpStar:
+			b.WriteByte('*')
+		case syntax.OpPlus:
+			b.WriteByte('+')
+		case syntax.OpQuest:
+			b.WriteByte('?')
+		default:
+			if re.Max == re.Min {
+				fmt.Fprintf(b, "{%d}", re.Min)
+			} else if re.Max < 0 {
+				fmt.Fprintf(b, "{%d,}", re.Min)
+			} else {
+				fmt.Fprintf(b, "{%d,%d}", re.Min, re.Max)
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := writeLucene(b, sub, true); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		if grouped {
+			b.WriteByte('(')
+		}
+		for i, sub := range re.Sub {
+			if i > 0 {
+				b.WriteByte('|')
+			}
+			if err := writeLucene(b, sub, false); err != nil {
+				return err
+			}
+		}
+		if grouped {
+			b.WriteByte(')')
+		}
+	default:
+		return fmt.Errorf("%s is not supported by lucene regexp", re.String())
+	}
+	return nil
+}
+
+// writeLuceneAtom write the operand of a repetition, it is wrapped in parentheses unless it is a single atom
+func writeLuceneAtom(b *strings.Builder, re *syntax.Regexp) error {
+	single := re.Op == syntax.OpCharClass || re.Op == syntax.OpAnyChar || re.Op == syntax.OpAnyCharNotNL ||
+		re.Op == syntax.OpCapture || (re.Op == syntax.OpLiteral && len(re.Rune) == 1 && re.Flags&syntax.FoldCase == 0)
+	if single {
+		return writeLucene(b, re, false)
+	}
+	b.WriteByte('(')
+	if err := writeLucene(b, re, false); err != nil {
+		return err
+	}
+	b.WriteByte(')')
+	return nil
+}
+
+// writeLuceneClass write the class of rune ranges, a class including the rune 0 and the max rune is negated
+func writeLuceneClass(b *strings.Builder, ranges []rune) {
+	b.WriteByte('[')
+	if len(ranges) > 0 && ranges[0] == 0 && ranges[len(ranges)-1] == unicode.MaxRune {
+		b.WriteByte('^')
+		var negated []rune
+		for i := 1; i+1 < len(ranges); i += 2 {
+			negated = append(negated, ranges[i]+1, ranges[i+1]-1)
+		}
+		ranges = negated
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		writeLuceneRune(b, ranges[i])
+		if ranges[i+1] != ranges[i] {
+			b.WriteByte('-')
+			writeLuceneRune(b, ranges[i+1])
+		}
+	}
+	b.WriteByte(']')
+}
+
+// writeLuceneRune write a literal rune, the reserved characters are escaped by backslash,
+// the others are written as they are, lucene reads \t as t rather than a tab
+func writeLuceneRune(b *strings.Builder, r rune) {
+	if r < unicode.MaxASCII && strings.ContainsRune(luceneReserved, r) {
+		b.WriteByte('\\')
+	}
+	b.WriteRune(r)
+}
+
+// ToElasticsearch translate the searchers into an elasticsearch query,
+// match is put into bool.must and scores the documents,
+// neq/notcontain are put into bool.must_not, the others are put into bool.filter.
+// contain/fuzzy are translated to wildcard, which works on keyword fields,
+// regex is translated to lucene regexp by luceneRegexp
+func (s *SearcherLimit) ToElasticsearch(searchers []*Searcher) (query M, err error) {
+	if searchers, err = s.compile(searchers); err != nil {
+		return nil, err
+	}
+	var must, filter, mustNot []interface{}
+	for k, searcher := range searchers {
+		field := searcher.Field
+		switch searcher.SearchOperator {
+		case SEARCH_OPERATOR_LESS:
+			filter = append(filter, M{"range": M{field: M{"lt": searcher.value}}})
+		case SEARCH_OPERATOR_LESS_EQUAL:
+			filter = append(filter, M{"range": M{field: M{"lte": searcher.value}}})
+		case SEARCH_OPERATOR_EQUAL:
+			filter = append(filter, M{"term": M{field: searcher.value}})
+		case SEARCH_OPERATOR_GREATER_EQUAL:
+			filter = append(filter, M{"range": M{field: M{"gte": searcher.value}}})
+		case SEARCH_OPERATOR_GREATER:
+			filter = append(filter, M{"range": M{field: M{"gt": searcher.value}}})
+		case SEARCH_OPERATOR_NOT_EQUAL:
+			mustNot = append(mustNot, M{"term": M{field: searcher.value}})
+		case SEARCH_OPERATOR_IN:
+			filter = append(filter, M{"terms": M{field: searcher.value}})
+		case SEARCH_OPERATOR_CONTAIN_OR:
+			filter = append(filter, M{"wildcard": M{field: M{"value": "*" + escapeWildcard(searcher.Value) + "*"}}})
+		case SEARCH_OPERATOR_NOT_CONTAIN:
+			mustNot = append(mustNot, M{"wildcard": M{field: M{"value": "*" + escapeWildcard(searcher.Value) + "*"}}})
+		case SEARCH_OPERATOR_PREFIX:
+			filter = append(filter, M{"prefix": M{field: M{"value": searcher.Value}}})
+		case SEARCH_OPERATOR_FUZZY:
+			var b strings.Builder
+			b.WriteByte('*')
+			for _, r := range searcher.Value {
+				b.WriteString(escapeWildcard(string(r)))
+				b.WriteByte('*')
+			}
+			filter = append(filter, M{"wildcard": M{field: M{"value": b.String(), "case_insensitive": true}}})
+		case SEARCH_OPERATOR_REGEX:
+			pattern, err := luceneRegexp(searcher.Value)
+			if err != nil {
+				return nil, fmt.Errorf("searchers[%d] can not be translated to elasticsearch query, %s", k, err.Error())
+			}
+			filter = append(filter, M{"regexp": M{field: M{"value": pattern}}})
+		case SEARCH_OPERATOR_MATCH:
+			must = append(must, M{"match": M{field: M{"query": searcher.Value, "operator": "and"}}})
+		default:
+			return nil, fmt.Errorf("searchers[%d] can not be translated to elasticsearch query, %s is not supported",
+				k, searchOperatorName[searcher.SearchOperator])
+		}
+	}
+	boolQuery := M{}
+	if len(must) > 0 {
+		boolQuery["must"] = must
+	}
+	if len(filter) > 0 {
+		boolQuery["filter"] = filter
+	}
+	if len(mustNot) > 0 {
+		boolQuery["must_not"] = mustNot
+	}
+	if len(boolQuery) == 0 {
+		return M{"query": M{"match_all": M{}}}, nil
+	}
+	return M{"query": M{"bool": boolQuery}}, nil
+}
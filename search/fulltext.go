@@ -0,0 +1,176 @@
+// Full-text search
+// Fields whose search tag has match can be indexed by an inverted index,
+// hits found by the index are scored with BM25
+
+package search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// matchTerms check whether all the query terms are in terms
+func matchTerms(terms []string, queryTerms []string) bool {
+	if len(queryTerms) == 0 {
+		return false
+	}
+	for _, q := range queryTerms {
+		found := false
+		for _, term := range terms {
+			if term == q {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// invertedIndex term -> row id -> term frequency
+type invertedIndex struct {
+	tokenizer Tokenizer
+	postings  map[string]map[uint64]int
+	docLen    map[uint64]int
+	totalLen  int
+}
+
+func newInvertedIndex(tokenizer Tokenizer) *invertedIndex {
+	return &invertedIndex{
+		tokenizer: tokenizer,
+		postings:  make(map[string]map[uint64]int),
+		docLen:    make(map[uint64]int),
+	}
+}
+
+func (ii *invertedIndex) insert(text string, id uint64) {
+	terms := ii.tokenizer.Tokenize(text)
+	for _, term := range terms {
+		ids, ok := ii.postings[term]
+		if !ok {
+			ids = make(map[uint64]int)
+			ii.postings[term] = ids
+		}
+		ids[id]++
+	}
+	ii.docLen[id] = len(terms)
+	ii.totalLen += len(terms)
+}
+
+func (ii *invertedIndex) remove(text string, id uint64) {
+	for _, term := range ii.tokenizer.Tokenize(text) {
+		ids := ii.postings[term]
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(ii.postings, term)
+		}
+	}
+	ii.totalLen -= ii.docLen[id]
+	delete(ii.docLen, id)
+}
+
+// lookup call fn for every row id which contains all the terms
+func (ii *invertedIndex) lookup(terms []string, fn func(id uint64)) {
+	if len(terms) == 0 {
+		return
+	}
+	shortest := ii.postings[terms[0]]
+	for _, term := range terms[1:] {
+		if ids := ii.postings[term]; len(ids) < len(shortest) {
+			shortest = ids
+		}
+	}
+	for id := range shortest {
+		all := true
+		for _, term := range terms {
+			if _, ok := ii.postings[term][id]; !ok {
+				all = false
+				break
+			}
+		}
+		if all {
+			fn(id)
+		}
+	}
+}
+
+// score the BM25 score of row id for terms
+func (ii *invertedIndex) score(terms []string, id uint64) float64 {
+	n := float64(len(ii.docLen))
+	if n == 0 {
+		return 0
+	}
+	avgLen := float64(ii.totalLen) / n
+	docLen := float64(ii.docLen[id])
+	var score float64
+	for _, term := range terms {
+		ids := ii.postings[term]
+		tf := float64(ids[id])
+		if tf == 0 {
+			continue
+		}
+		df := float64(len(ids))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*docLen/avgLen))
+	}
+	return score
+}
+
+// Hit a data found by search and its score
+type Hit struct {
+	Data       interface{}
+	Score      float64
+	Highlights map[string][]Highlight // where contain/prefix/regex/match hit, key is field's json name
+}
+
+// AddFullTextIndex add an inverted index for field, it is used by match,
+// the terms are split by the tokenizer of field in the limit
+func (c *Collection) AddFullTextIndex(field string) error {
+	tokenizer, ok := c.limit.tokenizerMap[field]
+	if !ok {
+		return fmt.Errorf("field(%s) does not support match", field)
+	}
+	return c.addIndex(field, &collectionIndex{field: field, fulltext: newInvertedIndex(tokenizer)})
+}
+
+// Search return the datas which meet all the searchers with their scores,
+// a match searcher on a full-text indexed field scores by BM25,
+// other searchers score like Rank, every score is multiplied by the field's boost,
+// hits are sorted by score from high to low and then by insertion order
+func (c *Collection) Search(searchers []*Searcher) (hits []*Hit, err error) {
+	if searchers, err = c.limit.compile(searchers); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	datas, ids := c.query(searchers)
+	hits = make([]*Hit, len(datas))
+	for k, data := range datas {
+		hits[k] = &Hit{Data: data, Highlights: c.limit.highlightData(searchers, data)}
+		for _, s := range searchers {
+			hits[k].Score += c.limit.limit[s.Field].Boost * c.score(s, data, ids[k])
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	return hits, nil
+}
+
+// score the score of data matched by the searcher, the caller must hold the read lock
+func (c *Collection) score(s *Searcher, data interface{}, id uint64) float64 {
+	if s.SearchOperator == SEARCH_OPERATOR_MATCH {
+		for _, index := range c.indexes {
+			if index.fulltext != nil && index.field == s.Field {
+				return index.fulltext.score(s.value.([]string), id)
+			}
+		}
+	}
+	return s.score(data)
+}
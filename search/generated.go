@@ -0,0 +1,64 @@
+// Runtime of the matchers generated by searchgen
+// The generated code reads the fields directly, so neither unsafe nor a kind switch is needed per row,
+// the searchers are still checked and converted by the limit, so the results are the same as Filter
+
+package search
+
+import (
+	"regexp"
+
+	"golang.org/x/exp/constraints"
+)
+
+// GenCondition a compiled searcher used by the generated code, it should not be used directly
+type GenCondition struct {
+	searcher *Searcher
+}
+
+// Field the json name of the searcher's field
+func (c GenCondition) Field() string {
+	return c.searcher.Field
+}
+
+// GenConditions the compiled searchers of the query for the generated code
+func (q *Query) GenConditions() []GenCondition {
+	conds := make([]GenCondition, len(q.searchers))
+	for k, searcher := range q.searchers {
+		conds[k] = GenCondition{searcher: searcher}
+	}
+	return conds
+}
+
+// GenMatchNumber check whether the number field value v meets the condition,
+// the type of v must be the same as the kind of the field in schema
+func GenMatchNumber[K constraints.Integer | constraints.Float](v K, c GenCondition) bool {
+	s := c.searcher
+	if s.SearchOperator == SEARCH_OPERATOR_IN {
+		for _, value := range s.value.([]interface{}) {
+			if value == interface{}(v) {
+				return true
+			}
+		}
+		return false
+	}
+	return doNumbericMatch(v, s.value.(K), s.SearchOperator)
+}
+
+// GenMatchString check whether the string field value v meets the condition
+func GenMatchString(v string, c GenCondition) bool {
+	s := c.searcher
+	switch s.SearchOperator {
+	case SEARCH_OPERATOR_IN:
+		for _, value := range s.value.([]interface{}) {
+			if value == interface{}(v) {
+				return true
+			}
+		}
+		return false
+	case SEARCH_OPERATOR_MATCH:
+		return matchTerms(s.tokenizer.Tokenize(v), s.value.([]string))
+	case SEARCH_OPERATOR_REGEX:
+		return s.value.(*regexp.Regexp).MatchString(v)
+	}
+	return doStringMatch(v, s.value.(string), s.SearchOperator)
+}
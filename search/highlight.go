@@ -0,0 +1,261 @@
+// Match highlighting
+// contain/prefix/regex/match searchers report where they hit the string field,
+// match highlights the tokens of the field's tokenizer which are the searcher's terms,
+// Snippet renders the hits with markers
+
+package search
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Highlight a matched part of a field's value, End is exclusive
+type Highlight struct {
+	Start     int // byte offset of the start
+	End       int // byte offset of the end
+	RuneStart int // rune offset of the start
+	RuneEnd   int // rune offset of the end
+}
+
+// indexFold find all the parts of str equal to sub under case folding,
+// the parts may differ from sub in bytes, e.g. "ſ" folds to "s"
+func indexFold(str, sub string) (ranges [][2]int) {
+	if sub == "" {
+		return nil
+	}
+	for i := 0; i < len(str); {
+		if n := prefixFold(str[i:], sub); n > 0 {
+			ranges = append(ranges, [2]int{i, i + n})
+			i += n
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(str[i:])
+		i += size
+	}
+	return ranges
+}
+
+// prefixFold the byte length of the prefix of str which equals sub under case folding, 0 if there is none
+func prefixFold(str, sub string) int {
+	i := 0
+	for _, r := range sub {
+		if i >= len(str) {
+			return 0
+		}
+		c, size := utf8.DecodeRuneInString(str[i:])
+		if !equalFoldRune(c, r) {
+			return 0
+		}
+		i += size
+	}
+	return i
+}
+
+// equalFoldRune whether a and b are the same rune under simple case folding
+func equalFoldRune(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	for f := unicode.SimpleFold(a); f != a; f = unicode.SimpleFold(f) {
+		if f == b {
+			return true
+		}
+	}
+	return false
+}
+
+// highlights find where the valid searcher hits the data
+func (s *Searcher) highlights(in interface{}) (ranges [][2]int) {
+	if s.fieldKind != reflect.String {
+		return nil
+	}
+	str := stringValue(fieldAt(in, s.fieldInfo))
+	switch s.SearchOperator {
+	case SEARCH_OPERATOR_CONTAIN_OR:
+		value := s.value.(string)
+		if value == "" {
+			return nil
+		}
+		for start := 0; ; {
+			i := strings.Index(str[start:], value)
+			if i < 0 {
+				break
+			}
+			ranges = append(ranges, [2]int{start + i, start + i + len(value)})
+			start += i + len(value)
+		}
+	case SEARCH_OPERATOR_PREFIX:
+		if value := s.value.(string); value != "" && strings.HasPrefix(str, value) {
+			ranges = append(ranges, [2]int{0, len(value)})
+		}
+	case SEARCH_OPERATOR_REGEX:
+		for _, loc := range s.value.(*regexp.Regexp).FindAllStringIndex(str, -1) {
+			if loc[0] < loc[1] {
+				ranges = append(ranges, [2]int{loc[0], loc[1]})
+			}
+		}
+	case SEARCH_OPERATOR_MATCH:
+		terms := s.value.([]string)
+		if tokenizer, ok := s.tokenizer.(SpanTokenizer); ok {
+			// the tokens of the field which are terms of the searcher
+			for _, token := range tokenizer.TokenizeSpans(str) {
+				for _, term := range terms {
+					if token.Term == term {
+						ranges = append(ranges, [2]int{token.Start, token.End})
+						break
+					}
+				}
+			}
+			break
+		}
+		// a tokenizer without positions, the terms are found ignoring case
+		for _, term := range terms {
+			ranges = append(ranges, indexFold(str, term)...)
+		}
+	}
+	return ranges
+}
+
+// toHighlights sort and merge the overlapping ranges of str and count their rune offsets
+func toHighlights(str string, ranges [][2]int) []Highlight {
+	if len(ranges) == 0 {
+		return nil
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r[0] <= last[1] {
+			if r[1] > last[1] {
+				last[1] = r[1]
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	highlights := make([]Highlight, len(merged))
+	pos, runePos := 0, 0
+	for k, r := range merged {
+		runePos += utf8.RuneCountInString(str[pos:r[0]])
+		highlights[k].Start, highlights[k].RuneStart = r[0], runePos
+		runePos += utf8.RuneCountInString(str[r[0]:r[1]])
+		highlights[k].End, highlights[k].RuneEnd = r[1], runePos
+		pos = r[1]
+	}
+	return highlights
+}
+
+// highlightData find where all the valid searchers hit data, key is the field's json name
+func (s *SearcherLimit) highlightData(searchers []*Searcher, data interface{}) map[string][]Highlight {
+	var fieldRanges map[string][][2]int
+	for _, searcher := range searchers {
+		ranges := searcher.highlights(data)
+		if len(ranges) == 0 {
+			continue
+		}
+		if fieldRanges == nil {
+			fieldRanges = make(map[string][][2]int)
+		}
+		fieldRanges[searcher.Field] = append(fieldRanges[searcher.Field], ranges...)
+	}
+	if fieldRanges == nil {
+		return nil
+	}
+	highlights := make(map[string][]Highlight, len(fieldRanges))
+	for field, ranges := range fieldRanges {
+		str := stringValue(fieldAt(data, s.fieldInfoMap[field]))
+		highlights[field] = toHighlights(str, ranges)
+	}
+	return highlights
+}
+
+// SnippetOptions options of Snippet
+type SnippetOptions struct {
+	PreTag   string // marker before the highlight, default is <em>
+	PostTag  string // marker after the highlight, default is </em>
+	Context  int    // number of runes kept around the highlights, 0 means keeping the whole text
+	Ellipsis string // placeholder of the omitted text, default is ...
+}
+
+// Snippet render text with the highlights wrapped by markers,
+// with a positive Context only the fragments around the highlights are kept
+func Snippet(text string, highlights []Highlight, opts SnippetOptions) string {
+	if opts.PreTag == "" && opts.PostTag == "" {
+		opts.PreTag, opts.PostTag = "<em>", "</em>"
+	}
+	if opts.Ellipsis == "" {
+		opts.Ellipsis = "..."
+	}
+	if len(highlights) == 0 {
+		if opts.Context <= 0 {
+			return text
+		}
+		return firstRunes(text, opts.Context, opts.Ellipsis)
+	}
+	var b strings.Builder
+	pos := 0 // end of the written text
+	for k, h := range highlights {
+		from := pos
+		if opts.Context > 0 {
+			from = backRunes(text, h.Start, opts.Context)
+			if from < pos {
+				from = pos
+			}
+			if from > pos { // the text between fragments is omitted
+				b.WriteString(opts.Ellipsis)
+			}
+		}
+		b.WriteString(text[from:h.Start])
+		b.WriteString(opts.PreTag)
+		b.WriteString(text[h.Start:h.End])
+		b.WriteString(opts.PostTag)
+		pos = h.End
+		if opts.Context <= 0 {
+			continue
+		}
+		to := forwardRunes(text, h.End, opts.Context)
+		if k+1 < len(highlights) && to > highlights[k+1].Start {
+			to = highlights[k+1].Start
+		}
+		b.WriteString(text[h.End:to])
+		pos = to
+	}
+	if opts.Context <= 0 {
+		b.WriteString(text[pos:])
+	} else if pos < len(text) {
+		b.WriteString(opts.Ellipsis)
+	}
+	return b.String()
+}
+
+// backRunes the byte offset n runes before end
+func backRunes(text string, end int, n int) int {
+	for ; n > 0 && end > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(text[:end])
+		end -= size
+	}
+	return end
+}
+
+// forwardRunes the byte offset n runes after start
+func forwardRunes(text string, start int, n int) int {
+	for ; n > 0 && start < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[start:])
+		start += size
+	}
+	return start
+}
+
+// firstRunes the first n runes of text
+func firstRunes(text string, n int, ellipsis string) string {
+	end := forwardRunes(text, 0, n)
+	if end == len(text) {
+		return text
+	}
+	return text[:end] + ellipsis
+}
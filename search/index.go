@@ -0,0 +1,236 @@
+// Secondary indexes used by Collection
+// hashIndex serves eq/in, orderedIndex (a skip list) serves range operators
+
+package search
+
+import (
+	"strings"
+
+	"golang.org/x/exp/constraints"
+)
+
+// compareOrdered compare two ordered values, return -1/0/1,
+// NaN is ordered before the other values and equals NaN, so the ordered index stays a total order
+func compareOrdered[K1 constraints.Ordered](left, right K1) int {
+	if leftNaN, rightNaN := left != left, right != right; leftNaN || rightNaN {
+		if leftNaN && rightNaN {
+			return 0
+		}
+		if leftNaN {
+			return -1
+		}
+		return 1
+	}
+	if left < right {
+		return -1
+	}
+	if left > right {
+		return 1
+	}
+	return 0
+}
+
+// compareValue compare two field values which have the same type
+func compareValue(left, right interface{}) int {
+	switch l := left.(type) {
+	case int:
+		return compareOrdered(l, right.(int))
+	case int8:
+		return compareOrdered(l, right.(int8))
+	case int16:
+		return compareOrdered(l, right.(int16))
+	case int32:
+		return compareOrdered(l, right.(int32))
+	case int64:
+		return compareOrdered(l, right.(int64))
+	case uint:
+		return compareOrdered(l, right.(uint))
+	case uint8:
+		return compareOrdered(l, right.(uint8))
+	case uint16:
+		return compareOrdered(l, right.(uint16))
+	case uint32:
+		return compareOrdered(l, right.(uint32))
+	case uint64:
+		return compareOrdered(l, right.(uint64))
+	case float32:
+		return compareOrdered(l, right.(float32))
+	case float64:
+		return compareOrdered(l, right.(float64))
+	case string:
+		return strings.Compare(l, right.(string))
+	}
+	return 0
+}
+
+// isNaN whether the field value is a float NaN, which never meets a search condition like Filter
+func isNaN(value interface{}) bool {
+	switch v := value.(type) {
+	case float32:
+		return v != v
+	case float64:
+		return v != v
+	}
+	return false
+}
+
+// hashIndex field value -> row ids, NaN is not a usable map key so its rows are not indexed
+type hashIndex struct {
+	ids map[interface{}]map[uint64]struct{}
+}
+
+func newHashIndex() *hashIndex {
+	return &hashIndex{ids: make(map[interface{}]map[uint64]struct{})}
+}
+
+func (h *hashIndex) insert(key interface{}, id uint64) {
+	if isNaN(key) {
+		return
+	}
+	ids, ok := h.ids[key]
+	if !ok {
+		ids = make(map[uint64]struct{})
+		h.ids[key] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+func (h *hashIndex) remove(key interface{}, id uint64) {
+	if isNaN(key) {
+		return
+	}
+	ids, ok := h.ids[key]
+	if !ok {
+		return
+	}
+	delete(ids, id)
+	if len(ids) == 0 {
+		delete(h.ids, key)
+	}
+}
+
+// lookup call fn for every row id whose field value is key
+func (h *hashIndex) lookup(key interface{}, fn func(id uint64)) {
+	for id := range h.ids[key] {
+		fn(id)
+	}
+}
+
+const skipListMaxLevel = 24
+
+type skipListNode struct {
+	key  interface{}
+	id   uint64
+	next []*skipListNode
+}
+
+// orderedIndex a skip list sorted by (field value, row id)
+type orderedIndex struct {
+	head  *skipListNode
+	level int
+	seed  uint64
+}
+
+func newOrderedIndex() *orderedIndex {
+	return &orderedIndex{
+		head:  &skipListNode{next: make([]*skipListNode, skipListMaxLevel)},
+		level: 1,
+		seed:  0x9E3779B97F4A7C15,
+	}
+}
+
+// randomLevel each level is promoted with probability 1/4
+func (o *orderedIndex) randomLevel() int {
+	o.seed ^= o.seed << 13
+	o.seed ^= o.seed >> 7
+	o.seed ^= o.seed << 17
+	level := 1
+	for r := o.seed; level < skipListMaxLevel && r&3 == 0; r >>= 2 {
+		level++
+	}
+	return level
+}
+
+// before check whether node n is sorted before (key, id)
+func (o *orderedIndex) before(n *skipListNode, key interface{}, id uint64) bool {
+	c := compareValue(n.key, key)
+	return c < 0 || (c == 0 && n.id < id)
+}
+
+func (o *orderedIndex) insert(key interface{}, id uint64) {
+	var update [skipListMaxLevel]*skipListNode
+	n := o.head
+	for i := o.level - 1; i >= 0; i-- {
+		for n.next[i] != nil && o.before(n.next[i], key, id) {
+			n = n.next[i]
+		}
+		update[i] = n
+	}
+	level := o.randomLevel()
+	if level > o.level {
+		for i := o.level; i < level; i++ {
+			update[i] = o.head
+		}
+		o.level = level
+	}
+	node := &skipListNode{key: key, id: id, next: make([]*skipListNode, level)}
+	for i := 0; i < level; i++ {
+		node.next[i] = update[i].next[i]
+		update[i].next[i] = node
+	}
+}
+
+func (o *orderedIndex) remove(key interface{}, id uint64) {
+	var update [skipListMaxLevel]*skipListNode
+	n := o.head
+	for i := o.level - 1; i >= 0; i-- {
+		for n.next[i] != nil && o.before(n.next[i], key, id) {
+			n = n.next[i]
+		}
+		update[i] = n
+	}
+	node := n.next[0]
+	if node == nil || node.id != id || compareValue(node.key, key) != 0 {
+		return
+	}
+	for i := 0; i < len(node.next); i++ {
+		update[i].next[i] = node.next[i]
+	}
+	for o.level > 1 && o.head.next[o.level-1] == nil {
+		o.level--
+	}
+}
+
+// scan call fn for every row id whose field value is in the range,
+// a nil bound means the range is unbounded on that side, a NaN bound or field value is never in the range
+func (o *orderedIndex) scan(
+	lower interface{}, lowerInclusive bool, upper interface{}, upperInclusive bool, fn func(id uint64),
+) {
+	if isNaN(lower) || isNaN(upper) {
+		return
+	}
+	n := o.head
+	if lower != nil {
+		for i := o.level - 1; i >= 0; i-- {
+			for n.next[i] != nil {
+				c := compareValue(n.next[i].key, lower)
+				if c > 0 || (c == 0 && lowerInclusive) {
+					break
+				}
+				n = n.next[i]
+			}
+		}
+	}
+	for n = n.next[0]; n != nil; n = n.next[0] {
+		if isNaN(n.key) {
+			continue
+		}
+		if upper != nil {
+			c := compareValue(n.key, upper)
+			if c > 0 || (c == 0 && !upperInclusive) {
+				return
+			}
+		}
+		fn(n.id)
+	}
+}
@@ -0,0 +1,284 @@
+// Filter raw json documents and map rows
+// The limit is still built from a struct which declares the searchable fields and their types,
+// only the fields referenced by the searchers are extracted from the documents
+
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// jsonScanner a minimal scanner which extracts the top level fields of a json object
+type jsonScanner struct {
+	data []byte
+	pos  int
+}
+
+var errInvalidJSON = errors.New("invalid json")
+
+func (js *jsonScanner) skipSpace() {
+	for js.pos < len(js.data) {
+		switch js.data[js.pos] {
+		case ' ', '\t', '\n', '\r':
+			js.pos++
+		default:
+			return
+		}
+	}
+}
+
+// expect consume the byte c after white space
+func (js *jsonScanner) expect(c byte) error {
+	js.skipSpace()
+	if js.pos >= len(js.data) || js.data[js.pos] != c {
+		return errInvalidJSON
+	}
+	js.pos++
+	return nil
+}
+
+// scanString consume a string and return it unquoted
+func (js *jsonScanner) scanString() (string, error) {
+	start := js.pos
+	if js.pos >= len(js.data) || js.data[js.pos] != '"' {
+		return "", errInvalidJSON
+	}
+	escaped := false
+	for js.pos++; js.pos < len(js.data); js.pos++ {
+		switch js.data[js.pos] {
+		case '\\':
+			escaped = true
+			js.pos++
+		case '"':
+			js.pos++
+			if !escaped {
+				return string(js.data[start+1 : js.pos-1]), nil
+			}
+			var str string
+			if err := json.Unmarshal(js.data[start:js.pos], &str); err != nil {
+				return "", err
+			}
+			return str, nil
+		}
+	}
+	return "", errInvalidJSON
+}
+
+// skipValue consume a value of any type
+func (js *jsonScanner) skipValue() error {
+	js.skipSpace()
+	if js.pos >= len(js.data) {
+		return errInvalidJSON
+	}
+	switch js.data[js.pos] {
+	case '"':
+		_, err := js.scanString()
+		return err
+	case '{', '[':
+		depth := 0
+		for ; js.pos < len(js.data); js.pos++ {
+			switch js.data[js.pos] {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					js.pos++
+					return nil
+				}
+			case '"':
+				if _, err := js.scanString(); err != nil {
+					return err
+				}
+				js.pos--
+			}
+		}
+		return errInvalidJSON
+	}
+	_, err := js.scanLiteral()
+	return err
+}
+
+// scanLiteral consume a number, true, false or null and return its text
+func (js *jsonScanner) scanLiteral() (string, error) {
+	start := js.pos
+	for ; js.pos < len(js.data); js.pos++ {
+		c := js.data[js.pos]
+		if c == ',' || c == '}' || c == ']' || c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			break
+		}
+	}
+	literal := string(js.data[start:js.pos])
+	if literal != "true" && literal != "false" && literal != "null" && !isJSONNumber(literal) {
+		return "", errInvalidJSON
+	}
+	return literal, nil
+}
+
+// isJSONNumber check whether s is a number of json: -?(0|[1-9][0-9]*)(.[0-9]+)?([eE][+-]?[0-9]+)?
+func isJSONNumber(s string) bool {
+	i := 0
+	digits := func() bool {
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		return i > start
+	}
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	if i < len(s) && s[i] == '0' {
+		i++
+	} else if !digits() {
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		if !digits() {
+			return false
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if !digits() {
+			return false
+		}
+	}
+	return i == len(s)
+}
+
+// scanValue consume a value, strings/numbers/booleans are returned as strings,
+// null/objects/arrays are returned as nil
+func (js *jsonScanner) scanValue() (interface{}, error) {
+	js.skipSpace()
+	if js.pos >= len(js.data) {
+		return nil, errInvalidJSON
+	}
+	switch js.data[js.pos] {
+	case '"':
+		return js.scanString()
+	case '{', '[':
+		return nil, js.skipValue()
+	}
+	literal, err := js.scanLiteral()
+	if err != nil || literal == "null" {
+		return nil, err
+	}
+	return literal, nil
+}
+
+// extract the top level fields of the object, the whole document is scanned so an invalid one is rejected,
+// a repeated key keeps its last value like encoding/json
+func (js *jsonScanner) extract(fields map[string]interface{}) error {
+	if err := js.expect('{'); err != nil {
+		return err
+	}
+	js.skipSpace()
+	if js.pos < len(js.data) && js.data[js.pos] == '}' {
+		js.pos++
+		return js.end()
+	}
+	for {
+		js.skipSpace()
+		key, err := js.scanString()
+		if err != nil {
+			return err
+		}
+		if err = js.expect(':'); err != nil {
+			return err
+		}
+		if _, ok := fields[key]; ok {
+			if fields[key], err = js.scanValue(); err != nil {
+				return err
+			}
+		} else if err = js.skipValue(); err != nil {
+			return err
+		}
+		js.skipSpace()
+		if js.pos >= len(js.data) {
+			return errInvalidJSON
+		}
+		if js.data[js.pos] == '}' {
+			js.pos++
+			return js.end()
+		}
+		if js.data[js.pos] != ',' {
+			return errInvalidJSON
+		}
+		js.pos++
+	}
+}
+
+// end check that only white space follows the object
+func (js *jsonScanner) end() error {
+	js.skipSpace()
+	if js.pos != len(js.data) {
+		return errInvalidJSON
+	}
+	return nil
+}
+
+// FilterJSON filter json objects by the searchers, the field of a searcher is the key of object,
+// a missing or null field does not match any searcher
+func (s *SearcherLimit) FilterJSON(searchers []*Searcher, docs [][]byte) (docsOut [][]byte, err error) {
+	if searchers, err = s.compile(searchers); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{}, len(searchers))
+	for i, doc := range docs {
+		for _, searcher := range searchers {
+			fields[searcher.Field] = nil
+		}
+		js := &jsonScanner{data: doc}
+		if err = js.extract(fields); err != nil {
+			return nil, fmt.Errorf("docs[%d] is invalid json: %s", i, err.Error())
+		}
+		matched := true
+		for _, searcher := range searchers {
+			if !searcher.matchValue(fields[searcher.Field]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			docsOut = append(docsOut, doc)
+		}
+	}
+	return docsOut, nil
+}
+
+// FilterMaps filter map rows decoded elsewhere by the searchers,
+// the values are converted to the types of the struct's fields before matching like FilterJSON,
+// e.g. 3.5 does not match an int field rather than being truncated to 3
+func (s *SearcherLimit) FilterMaps(
+	searchers []*Searcher, rows []map[string]interface{},
+) (rowsOut []map[string]interface{}, err error) {
+	if searchers, err = s.compile(searchers); err != nil {
+		return nil, err
+	}
+	for i, row := range rows {
+		if row == nil {
+			return nil, fmt.Errorf("rows[%d] is nil", i)
+		}
+		if matchRow(searchers, row) {
+			rowsOut = append(rowsOut, row)
+		}
+	}
+	return rowsOut, nil
+}
+
+// matchRow check whether the row meets all the valid searchers
+func matchRow(searchers []*Searcher, row map[string]interface{}) bool {
+	for _, searcher := range searchers {
+		if !searcher.matchValue(row[searcher.Field]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+// Translate searchers into a MongoDB filter document
+// The json names are mapped to field names by bson tag
+
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// M an unordered document, it has the same layout as bson.M
+type M map[string]interface{}
+
+// fuzzyRegex a regular expression which matches all the characters of value in order
+func fuzzyRegex(value string) string {
+	parts := make([]string, 0, len(value))
+	for _, r := range value {
+		parts = append(parts, regexp.QuoteMeta(string(r)))
+	}
+	return strings.Join(parts, ".*")
+}
+
+// ToMongo translate the searchers into a mongodb filter document,
+// one searcher is translated to {field: {operator: value}},
+// several searchers are combined with $and, no searcher means matching all.
+// contain/notcontain/prefix/fuzzy are translated to $regex, match is not supported
+func (s *SearcherLimit) ToMongo(searchers []*Searcher) (filter M, err error) {
+	if searchers, err = s.compile(searchers); err != nil {
+		return nil, err
+	}
+	conds := make([]interface{}, 0, len(searchers))
+	for k, searcher := range searchers {
+		var cond M
+		switch searcher.SearchOperator {
+		case SEARCH_OPERATOR_LESS:
+			cond = M{"$lt": searcher.value}
+		case SEARCH_OPERATOR_LESS_EQUAL:
+			cond = M{"$lte": searcher.value}
+		case SEARCH_OPERATOR_EQUAL:
+			cond = M{"$eq": searcher.value}
+		case SEARCH_OPERATOR_GREATER_EQUAL:
+			cond = M{"$gte": searcher.value}
+		case SEARCH_OPERATOR_GREATER:
+			cond = M{"$gt": searcher.value}
+		case SEARCH_OPERATOR_NOT_EQUAL:
+			cond = M{"$ne": searcher.value}
+		case SEARCH_OPERATOR_IN:
+			cond = M{"$in": searcher.value}
+		case SEARCH_OPERATOR_CONTAIN_OR:
+			cond = M{"$regex": regexp.QuoteMeta(searcher.Value)}
+		case SEARCH_OPERATOR_NOT_CONTAIN:
+			cond = M{"$not": M{"$regex": regexp.QuoteMeta(searcher.Value)}}
+		case SEARCH_OPERATOR_PREFIX:
+			cond = M{"$regex": "^" + regexp.QuoteMeta(searcher.Value)}
+		case SEARCH_OPERATOR_FUZZY:
+			cond = M{"$regex": fuzzyRegex(searcher.Value), "$options": "i"}
+		case SEARCH_OPERATOR_REGEX:
+			cond = M{"$regex": searcher.value.(*regexp.Regexp).String()}
+		default:
+			return nil, fmt.Errorf("searchers[%d] can not be translated to mongodb filter, %s is not supported",
+				k, searchOperatorName[searcher.SearchOperator])
+		}
+		conds = append(conds, M{s.fieldInfoMap[searcher.Field].bson: cond})
+	}
+	switch len(conds) {
+	case 0:
+		return M{}, nil
+	case 1:
+		return conds[0].(M), nil
+	}
+	return M{"$and": conds}, nil
+}
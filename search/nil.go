@@ -0,0 +1,22 @@
+// Nil checking
+// An interface holding a nil pointer is not equal to nil, e.g. interface{}((*T)(nil)) != nil,
+// so the input datas are checked by IsNil, which is explored in test/reflect_nil_check_test.go
+
+package search
+
+import (
+	"reflect"
+)
+
+// IsNil check whether v is nil, or holds a nil pointer, map, slice, func, chan or interface
+func IsNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface, reflect.UnsafePointer:
+		return rv.IsNil()
+	}
+	return false
+}
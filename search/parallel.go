@@ -0,0 +1,109 @@
+// Parallel filter for large slices
+// The datas are split into contiguous chunks, each chunk is filtered by a goroutine
+// and the results are concatenated in chunk order, so the input order is preserved
+
+package search
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// parallelThreshold slices shorter than it are filtered serially,
+// the cost of goroutines is larger than the cost of filtering below it
+const parallelThreshold = 8192
+
+// parallelCheckInterval the number of datas filtered between two checks of ctx
+const parallelCheckInterval = 1024
+
+// filterChunk filter datas[start:end] and return the matched datas,
+// the error is about the first invalid data or ctx
+func (s *SearcherLimit) filterChunk(
+	ctx context.Context, searchers []*Searcher, datas []interface{}, start, end int,
+) (datasOut []interface{}, err error) {
+	for i := start; i < end; i++ {
+		if (i-start)%parallelCheckInterval == 0 {
+			if err = ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if err = s.checkData(datas[i], i); err != nil {
+			return nil, err
+		}
+		if matchAll(searchers, datas[i]) {
+			datasOut = append(datasOut, datas[i])
+		}
+	}
+	return datasOut, nil
+}
+
+// FilterParallel return the datas which meet all the searchers in input order,
+// the datas are filtered by workers goroutines(GOMAXPROCS if workers <= 0),
+// it stays serial when the datas are few, and it stops early when ctx is done
+func (s *SearcherLimit) FilterParallel(
+	ctx context.Context, searchers []*Searcher, datasIn []interface{}, workers int,
+) (datasOut []interface{}, err error) {
+	if searchers, err = s.compile(searchers); err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if max := (len(datasIn) + parallelThreshold - 1) / parallelThreshold; workers > max {
+		workers = max
+	}
+	if workers <= 1 {
+		return s.filterChunk(ctx, searchers, datasIn, 0, len(datasIn))
+	}
+
+	// every chunk has its own ctx, a failed chunk cancels the chunks after it only,
+	// so the chunks before it still run and the invalid data of the smallest index is reported
+	ctxs := make([]context.Context, workers)
+	cancels := make([]context.CancelFunc, workers)
+	for w := 0; w < workers; w++ {
+		ctxs[w], cancels[w] = context.WithCancel(ctx)
+	}
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+	results := make([][]interface{}, workers)
+	errs := make([]error, workers)
+	size := (len(datasIn) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start, end := w*size, (w+1)*size
+		if end > len(datasIn) {
+			end = len(datasIn)
+		}
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			if results[w], errs[w] = s.filterChunk(ctxs[w], searchers, datasIn, start, end); errs[w] != nil {
+				for _, cancel := range cancels[w+1:] {
+					cancel() // the chunks after it are useless
+				}
+			}
+		}(w, start, end)
+	}
+	wg.Wait()
+
+	// an invalid data is reported before the cancellation caused by it
+	total := 0
+	for w := 0; w < workers; w++ {
+		if errs[w] != nil && errs[w] != context.Canceled {
+			return nil, errs[w]
+		}
+		total += len(results[w])
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	datasOut = make([]interface{}, 0, total)
+	for _, result := range results {
+		datasOut = append(datasOut, result...)
+	}
+	return datasOut, nil
+}
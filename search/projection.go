@@ -0,0 +1,253 @@
+// Field projection
+// Only the selected fields of each data are returned,
+// so that the whole struct does not need to be marshaled
+
+package search
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+// checkData check whether data is a non-nil pointer of the limit's struct type, i is its index in datasIn
+func (s *SearcherLimit) checkData(data interface{}, i int) error {
+	if problem := s.dataProblem(data); problem != "" {
+		return fmt.Errorf("datasIn[%d]%s", i, problem)
+	}
+	return nil
+}
+
+// checkParam check whether data, the single data passed to a call, is a non-nil pointer of the limit's struct type
+func (s *SearcherLimit) checkParam(data interface{}) error {
+	if problem := s.dataProblem(data); problem != "" {
+		return fmt.Errorf("param data%s", problem)
+	}
+	return nil
+}
+
+// dataProblem describe why data is not a non-nil pointer of the limit's struct type, empty if it is
+func (s *SearcherLimit) dataProblem(data interface{}) string {
+	if data == nil {
+		return " is nil"
+	}
+	if IsNil(data) {
+		return " is a nil pointer"
+	}
+	if typeOf(data) != s.structType {
+		return "'s type is invalid"
+	}
+	return ""
+}
+
+// getFieldInfos check the fields used by operate and return their infos
+func (s *SearcherLimit) getFieldInfos(fields []string, operate string) ([]*fieldInfo, error) {
+	infos := make([]*fieldInfo, len(fields))
+	for k, field := range fields {
+		info, ok := s.fieldInfoMap[field]
+		if !ok {
+			return nil, fmt.Errorf("field(%s) does not support %s", field, operate)
+		}
+		infos[k] = info
+	}
+	return infos, nil
+}
+
+// fieldValue get the value of the field referred by ref as the type of kind
+func fieldValue(ref fieldRef, kind reflect.Kind) interface{} {
+	switch kind {
+	case reflect.Bool:
+		return boolValue(ref)
+	case reflect.Int:
+		return int(intValue(ref, kind))
+	case reflect.Int8:
+		return int8(intValue(ref, kind))
+	case reflect.Int16:
+		return int16(intValue(ref, kind))
+	case reflect.Int32:
+		return int32(intValue(ref, kind))
+	case reflect.Int64:
+		return intValue(ref, kind)
+	case reflect.Uint:
+		return uint(uintValue(ref, kind))
+	case reflect.Uint8:
+		return uint8(uintValue(ref, kind))
+	case reflect.Uint16:
+		return uint16(uintValue(ref, kind))
+	case reflect.Uint32:
+		return uint32(uintValue(ref, kind))
+	case reflect.Uint64:
+		return uintValue(ref, kind)
+	case reflect.Float32:
+		return float32(floatValue(ref, kind))
+	case reflect.Float64:
+		return floatValue(ref, kind)
+	case reflect.String:
+		return stringValue(ref)
+	}
+	return nil
+}
+
+// Project return the selected fields of each data as a map keyed by json name
+func (s *SearcherLimit) Project(
+	fields []string, datasIn []interface{},
+) (datasOut []map[string]interface{}, err error) {
+	infos, err := s.getFieldInfos(fields, "projection")
+	if err != nil {
+		return nil, err
+	}
+	datasOut = make([]map[string]interface{}, 0, len(datasIn))
+	for i, data := range datasIn {
+		if err = s.checkData(data, i); err != nil {
+			return nil, err
+		}
+		m := make(map[string]interface{}, len(infos))
+		for k, info := range infos {
+			m[fields[k]] = fieldValue(fieldAt(data, info), info.kind)
+		}
+		datasOut = append(datasOut, m)
+	}
+	return datasOut, nil
+}
+
+// AppendJSON append the selected fields of data to dst as a json object
+func (s *SearcherLimit) AppendJSON(dst []byte, fields []string, data interface{}) ([]byte, error) {
+	infos, err := s.getFieldInfos(fields, "projection")
+	if err != nil {
+		return dst, err
+	}
+	if err = s.checkParam(data); err != nil {
+		return dst, err
+	}
+	if err = checkJSONObject(fields, infos, data); err != nil {
+		return dst, err
+	}
+	return appendJSONObject(dst, fields, infos, data), nil
+}
+
+// ProjectJSON write the selected fields of each data to w as a json array,
+// the datas are written one by one without building the whole output,
+// all the datas are checked before writing, so nothing is written for an invalid data,
+// but an error of w leaves the array written so far incomplete
+func (s *SearcherLimit) ProjectJSON(w io.Writer, fields []string, datasIn []interface{}) error {
+	infos, err := s.getFieldInfos(fields, "projection")
+	if err != nil {
+		return err
+	}
+	for i, data := range datasIn {
+		if err = s.checkData(data, i); err != nil {
+			return err
+		}
+		if err = checkJSONObject(fields, infos, data); err != nil {
+			return fmt.Errorf("datasIn[%d]'s %s", i, err.Error())
+		}
+	}
+	buf := make([]byte, 0, 256)
+	buf = append(buf, '[')
+	for i, data := range datasIn {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendJSONObject(buf, fields, infos, data)
+		if _, err = w.Write(buf); err != nil {
+			return err
+		}
+		buf = buf[:0]
+	}
+	buf = append(buf, ']')
+	_, err = w.Write(buf)
+	return err
+}
+
+// checkJSONObject check whether the fields of data can be encoded as json, NaN and Inf are not json numbers
+func checkJSONObject(fields []string, infos []*fieldInfo, data interface{}) error {
+	for k, info := range infos {
+		if info.kind != reflect.Float32 && info.kind != reflect.Float64 {
+			continue
+		}
+		if f := floatValue(fieldAt(data, info), info.kind); math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("field(%s) is an unsupported float value: %v", fields[k], f)
+		}
+	}
+	return nil
+}
+
+// appendJSONObject append fields of data to dst as a json object, data is checked by checkJSONObject
+func appendJSONObject(dst []byte, fields []string, infos []*fieldInfo, data interface{}) []byte {
+	dst = append(dst, '{')
+	for k, info := range infos {
+		if k > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendJSONString(dst, fields[k])
+		dst = append(dst, ':')
+		ref := fieldAt(data, info)
+		switch info.kind {
+		case reflect.Bool:
+			dst = strconv.AppendBool(dst, boolValue(ref))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			dst = strconv.AppendInt(dst, intValue(ref, info.kind), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			dst = strconv.AppendUint(dst, uintValue(ref, info.kind), 10)
+		case reflect.Float32, reflect.Float64:
+			bits := 64
+			f := floatValue(ref, info.kind)
+			if info.kind == reflect.Float32 {
+				bits = 32
+			}
+			dst = strconv.AppendFloat(dst, f, 'g', -1, bits)
+		case reflect.String:
+			dst = appendJSONString(dst, stringValue(ref))
+		default:
+			dst = append(dst, "null"...)
+		}
+	}
+	return append(dst, '}')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString append str to dst as a quoted json string
+func appendJSONString(dst []byte, str string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(str); {
+		c := str[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(str[i:])
+			if r == utf8.RuneError && size == 1 { // invalid utf8 is replaced like encoding/json
+				dst = append(dst, str[start:i]...)
+				dst = append(dst, "\\ufffd"...)
+				i += size
+				start = i
+				continue
+			}
+			i += size
+			continue
+		}
+		if c >= 0x20 && c != '"' && c != '\\' {
+			i++
+			continue
+		}
+		dst = append(dst, str[start:i]...)
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+		}
+		i++
+		start = i
+	}
+	dst = append(dst, str[start:]...)
+	return append(dst, '"')
+}
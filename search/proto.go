@@ -0,0 +1,183 @@
+// Search protobuf messages through protoreflect
+// The searchable fields are declared by the (search.search) field option in .proto files,
+// nested fields are named by their paths and repeated fields match if any element matches.
+// The option is found in the global registry by its name, so the generated package of search.proto
+// is linked by the messages which use it and this package does not import it
+
+package search
+
+import (
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// searchOptionName the full name of the field option which declares the search operators, see proto/search.proto
+const searchOptionName = "search.search"
+
+// searchOptionType find the registered extension type of the search option
+func searchOptionType() (protoreflect.ExtensionType, error) {
+	xt, err := protoregistry.GlobalTypes.FindExtensionByName(searchOptionName)
+	if err != nil {
+		return nil, fmt.Errorf("option(%s) is not registered, %s", searchOptionName, err.Error())
+	}
+	return xt, nil
+}
+
+// protoKind the kind of go type which the protobuf field is converted to
+func protoKind(fd protoreflect.FieldDescriptor) reflect.Kind {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return reflect.Bool
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind, protoreflect.EnumKind:
+		return reflect.Int32
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return reflect.Int64
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return reflect.Uint32
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return reflect.Uint64
+	case protoreflect.FloatKind:
+		return reflect.Float32
+	case protoreflect.DoubleKind:
+		return reflect.Float64
+	case protoreflect.StringKind:
+		return reflect.String
+	}
+	return reflect.Invalid
+}
+
+// protoValue the go value of a protobuf field's value, enum is converted to int32
+func protoValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	if fd.Kind() == protoreflect.EnumKind {
+		return int32(v.Enum())
+	}
+	return v.Interface()
+}
+
+// NewSearcherLimitFromDescriptor Construct a searcher limit for the protobuf messages of md,
+// the field of a searcher is the path of proto field names, such as address.city
+func NewSearcherLimitFromDescriptor(md protoreflect.MessageDescriptor) (*SearcherLimit, error) {
+	if md == nil {
+		return nil, fmt.Errorf("param md is nil")
+	}
+	xt, err := searchOptionType()
+	if err != nil {
+		return nil, err
+	}
+	s := &SearcherLimit{
+		limit:        make(map[string]*searchLimit),
+		fieldInfoMap: make(map[string]*fieldInfo),
+		tokenizerMap: make(map[string]Tokenizer),
+		messageDesc:  md,
+	}
+	visiting := map[protoreflect.FullName]bool{md.FullName(): true}
+	if err = s.addProtoFields(md, xt, "", nil, visiting); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// addProtoFields add the fields of md which have search option xt, the nested messages are walked recursively
+func (s *SearcherLimit) addProtoFields(
+	md protoreflect.MessageDescriptor, xt protoreflect.ExtensionType, prefix string,
+	path []protoreflect.FieldDescriptor, visiting map[protoreflect.FullName]bool,
+) error {
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		name := prefix + string(fd.Name())
+		fdPath := append(path[:len(path):len(path)], fd)
+		if fd.IsMap() {
+			continue
+		}
+		if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+			sub := fd.Message()
+			if visiting[sub.FullName()] { // recursive message
+				continue
+			}
+			visiting[sub.FullName()] = true
+			if err := s.addProtoFields(sub, xt, name+".", fdPath, visiting); err != nil {
+				return err
+			}
+			delete(visiting, sub.FullName())
+			continue
+		}
+		searchOption, _ := proto.GetExtension(fd.Options(), xt).(string)
+		if searchOption == "" {
+			continue
+		}
+		kind := protoKind(fd)
+		sLimit, tokenizer, err := newSearchLimit(kind, name, searchOption, "", "")
+		if err != nil {
+			return err
+		}
+		if tokenizer != nil {
+			s.tokenizerMap[name] = tokenizer
+		}
+		s.limit[name] = sLimit
+		s.fieldInfoMap[name] = &fieldInfo{kind: kind, column: name, bson: name, protoPath: fdPath}
+	}
+	return nil
+}
+
+// matchProtoPath check whether any value at path of m meets the valid searcher
+func (s *Searcher) matchProtoPath(m protoreflect.Message, path []protoreflect.FieldDescriptor) bool {
+	fd := path[0]
+	last := len(path) == 1
+	if fd.IsList() {
+		list := m.Get(fd).List()
+		for i := 0; i < list.Len(); i++ {
+			if last && s.matchValue(protoValue(fd, list.Get(i))) {
+				return true
+			}
+			if !last && s.matchProtoPath(list.Get(i).Message(), path[1:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if !last {
+		if !m.Has(fd) { // unset message has no value
+			return false
+		}
+		return s.matchProtoPath(m.Get(fd).Message(), path[1:])
+	}
+	return s.matchValue(protoValue(fd, m.Get(fd)))
+}
+
+// FilterMessages filter protobuf messages by the searchers,
+// the limit must be constructed by NewSearcherLimitFromDescriptor
+func (s *SearcherLimit) FilterMessages(
+	searchers []*Searcher, msgsIn []proto.Message,
+) (msgsOut []proto.Message, err error) {
+	if s.messageDesc == nil {
+		return nil, fmt.Errorf("the limit is not constructed from a message descriptor")
+	}
+	if searchers, err = s.compile(searchers); err != nil {
+		return nil, err
+	}
+	for i, msg := range msgsIn {
+		if msg == nil || !msg.ProtoReflect().IsValid() {
+			return nil, fmt.Errorf("msgsIn[%d] is nil", i)
+		}
+		m := msg.ProtoReflect()
+		if m.Descriptor().FullName() != s.messageDesc.FullName() {
+			return nil, fmt.Errorf("msgsIn[%d]'s type is invalid", i)
+		}
+		matched := true
+		for _, searcher := range searchers {
+			if !searcher.matchProtoPath(m, s.fieldInfoMap[searcher.Field].protoPath) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			msgsOut = append(msgsOut, msg)
+		}
+	}
+	return msgsOut, nil
+}
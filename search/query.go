@@ -0,0 +1,137 @@
+// Parse searchers from a query string
+// e.g. `age >= 18 and name contain "wz yao" && city in shanghai,beijing`
+
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// querySymbolMap symbol operators of query string
+var querySymbolMap = map[string]SearchOperator{
+	"=":  SEARCH_OPERATOR_EQUAL,
+	"==": SEARCH_OPERATOR_EQUAL,
+	"!=": SEARCH_OPERATOR_NOT_EQUAL,
+	"<":  SEARCH_OPERATOR_LESS,
+	"<=": SEARCH_OPERATOR_LESS_EQUAL,
+	">":  SEARCH_OPERATOR_GREATER,
+	">=": SEARCH_OPERATOR_GREATER_EQUAL,
+	"~":  SEARCH_OPERATOR_CONTAIN_OR,
+	"!~": SEARCH_OPERATOR_NOT_CONTAIN,
+}
+
+// queryLexer split the query string into words
+type queryLexer struct {
+	query string
+	pos   int
+}
+
+func (l *queryLexer) skipSpace() {
+	for l.pos < len(l.query) && (l.query[l.pos] == ' ' || l.query[l.pos] == '\t') {
+		l.pos++
+	}
+}
+
+// word return the next word which ends with white space or stop
+func (l *queryLexer) word(stop func(c byte) bool) string {
+	l.skipSpace()
+	start := l.pos
+	for l.pos < len(l.query) && l.query[l.pos] != ' ' && l.query[l.pos] != '\t' && !stop(l.query[l.pos]) {
+		l.pos++
+	}
+	return l.query[start:l.pos]
+}
+
+func isQueryField(c byte) bool {
+	return c == '_' || c == '.' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isQuerySymbol(c byte) bool {
+	return c == '=' || c == '!' || c == '<' || c == '>' || c == '~'
+}
+
+// value return the next value, which may be quoted by " or '
+func (l *queryLexer) value() (string, error) {
+	l.skipSpace()
+	if l.pos >= len(l.query) {
+		return "", fmt.Errorf("query position %d: expect value", l.pos)
+	}
+	quote := l.query[l.pos]
+	if quote != '"' && quote != '\'' {
+		return l.word(func(byte) bool { return false }), nil
+	}
+	start := l.pos
+	for l.pos++; l.pos < len(l.query); l.pos++ {
+		switch l.query[l.pos] {
+		case '\\':
+			if quote == '"' {
+				l.pos++
+			}
+		case quote:
+			l.pos++
+			if quote == '\'' { // single quoted value is raw
+				return l.query[start+1 : l.pos-1], nil
+			}
+			value, err := strconv.Unquote(l.query[start:l.pos])
+			if err != nil {
+				return "", fmt.Errorf("query position %d: %s", start, err.Error())
+			}
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("query position %d: unterminated quote", start)
+}
+
+// ParseQuery parse the conditions of query string into searchers,
+// a condition is `field operator value` and conditions are joined by and/&&,
+// operator is a name of search tag(eq/contain/in...) or a symbol(=/!=/</<=/>/>=/~/!~),
+// value can be quoted by " (with escapes) or ' (raw)
+func ParseQuery(query string) (searchers []*Searcher, err error) {
+	l := &queryLexer{query: query}
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.query) {
+			if len(searchers) > 0 {
+				return nil, fmt.Errorf("query position %d: expect condition after and", l.pos)
+			}
+			return nil, nil
+		}
+		start := l.pos
+		field := l.word(func(c byte) bool { return !isQueryField(c) })
+		if field == "" {
+			return nil, fmt.Errorf("query position %d: expect field", start)
+		}
+		l.skipSpace()
+		start = l.pos
+		var opStr string
+		if l.pos < len(l.query) && isQuerySymbol(l.query[l.pos]) {
+			opStr = l.word(func(c byte) bool { return !isQuerySymbol(c) })
+		} else {
+			opStr = l.word(func(c byte) bool { return !isQueryField(c) })
+		}
+		if opStr == "" {
+			return nil, fmt.Errorf("query position %d: expect search type", start)
+		}
+		op, ok := querySymbolMap[opStr]
+		if !ok {
+			if op, ok = searchOperatorMap[strings.ToLower(opStr)]; !ok {
+				return nil, fmt.Errorf("query position %d: not support search type(%s)", start, opStr)
+			}
+		}
+		value, err := l.value()
+		if err != nil {
+			return nil, err
+		}
+		searchers = append(searchers, &Searcher{Field: field, SearchOperator: op, Value: value})
+		l.skipSpace()
+		if l.pos >= len(l.query) {
+			return searchers, nil
+		}
+		start = l.pos
+		if and := l.word(func(byte) bool { return false }); and != "&&" && !strings.EqualFold(and, "and") {
+			return nil, fmt.Errorf("query position %d: expect and, got %s", start, and)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Registry of searcher limits keyed by type
+// The limit of a struct type is built on first use and cached,
+// the key is the pointer type, see typeKey.
+// A limit is not modified after it is built, so the shared limits are safe for concurrent use
+
+package search
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// registryEntry a lazily built limit
+type registryEntry struct {
+	once  sync.Once
+	limit *SearcherLimit
+	err   error
+}
+
+// registry typeKey of *T -> *registryEntry
+var registry sync.Map
+
+// limitOf return the cached limit of the pointer type of ptr, ptr may be a nil pointer
+func limitOf(ptr interface{}) (*SearcherLimit, error) {
+	typ := typeOf(ptr)
+	entry, ok := registry.Load(typ)
+	if !ok {
+		entry, _ = registry.LoadOrStore(typ, &registryEntry{})
+	}
+	e := entry.(*registryEntry)
+	e.once.Do(func() {
+		e.limit, e.err = NewSearcherLimit(reflect.New(reflect.TypeOf(ptr).Elem()).Interface())
+	})
+	return e.limit, e.err
+}
+
+// LimitFor return the limit of struct type T, it is built on first use and shared afterwards,
+// safe for concurrent use
+func LimitFor[T any]() (*SearcherLimit, error) {
+	return limitOf((*T)(nil))
+}
+
+// LimitOf return the limit of data's struct type like LimitFor, data is a struct or a pointer of struct
+func LimitOf(data interface{}) (*SearcherLimit, error) {
+	t := reflect.TypeOf(data)
+	if t == nil {
+		return nil, errors.New("param data is nil")
+	}
+	if t.Kind() != reflect.Ptr {
+		data = reflect.New(t).Interface()
+	}
+	return limitOf(data)
+}
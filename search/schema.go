@@ -0,0 +1,173 @@
+// Schema based searcher limit for dynamic rows
+// Rows loaded from CSV, YAML or generic SQL scans are map[string]interface{},
+// their fields, types and allowed operators are declared by a schema
+
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaKindMap type name in schema -> kind, bool is not listed because no search operator supports it
+var schemaKindMap = map[string]reflect.Kind{
+	"int":     reflect.Int,
+	"int8":    reflect.Int8,
+	"int16":   reflect.Int16,
+	"int32":   reflect.Int32,
+	"int64":   reflect.Int64,
+	"uint":    reflect.Uint,
+	"uint8":   reflect.Uint8,
+	"uint16":  reflect.Uint16,
+	"uint32":  reflect.Uint32,
+	"uint64":  reflect.Uint64,
+	"float32": reflect.Float32,
+	"float64": reflect.Float64,
+	"string":  reflect.String,
+}
+
+// SchemaField a searchable field of dynamic rows
+type SchemaField struct {
+	Name      string   `json:"name" yaml:"name"`           // key of the row
+	Type      string   `json:"type" yaml:"type"`           // int/int8~int64/uint/uint8~uint64/float32/float64/string
+	Operators []string `json:"operators" yaml:"operators"` // the same as the search tag of struct, e.g. lt/lte/eq
+	Tokenizer string   `json:"tokenizer,omitempty" yaml:"tokenizer,omitempty"`
+	Boost     float64  `json:"boost,omitempty" yaml:"boost,omitempty"` // 0 means the default boost 1
+}
+
+// Schema the searchable fields of dynamic rows
+type Schema struct {
+	Fields []SchemaField `json:"fields" yaml:"fields"`
+}
+
+// LoadSchemaJSON load schema from json
+func LoadSchemaJSON(data []byte) (*Schema, error) {
+	schema := &Schema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// LoadSchemaYAML load schema from yaml
+func LoadSchemaYAML(data []byte) (*Schema, error) {
+	schema := &Schema{}
+	if err := yaml.Unmarshal(data, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// NewSearcherLimitFromSchema Construct a searcher limit for map rows declared by schema,
+// the limit is used by FilterMaps and FilterJSON
+func NewSearcherLimitFromSchema(schema *Schema) (*SearcherLimit, error) {
+	if schema == nil {
+		return nil, fmt.Errorf("param schema is nil")
+	}
+	s := &SearcherLimit{
+		limit:        make(map[string]*searchLimit),
+		fieldInfoMap: make(map[string]*fieldInfo),
+		tokenizerMap: make(map[string]Tokenizer),
+	}
+	for k, field := range schema.Fields {
+		if field.Name == "" {
+			return nil, fmt.Errorf("fields[%d] has no name", k)
+		}
+		if _, ok := s.limit[field.Name]; ok {
+			return nil, fmt.Errorf("field(%s) is duplicated", field.Name)
+		}
+		kind, ok := schemaKindMap[strings.ToLower(field.Type)]
+		if !ok {
+			return nil, fmt.Errorf("field(%s) has unsupported type(%s)", field.Name, field.Type)
+		}
+		if len(field.Operators) == 0 {
+			return nil, fmt.Errorf("field(%s) has no search operators", field.Name)
+		}
+		boostTag := ""
+		if field.Boost != 0 {
+			boostTag = strconv.FormatFloat(field.Boost, 'g', -1, 64)
+		}
+		sLimit, tokenizer, err := newSearchLimit(
+			kind, field.Name, strings.Join(field.Operators, ","), boostTag, field.Tokenizer)
+		if err != nil {
+			return nil, err
+		}
+		if tokenizer != nil {
+			s.tokenizerMap[field.Name] = tokenizer
+		}
+		s.limit[field.Name] = sLimit
+		s.fieldInfoMap[field.Name] = &fieldInfo{kind: kind, column: field.Name, bson: field.Name}
+	}
+	return s, nil
+}
+
+// inferKind infer the kind of value, string values are parsed as numbers first,
+// integers are parsed in base 10 like castValueE, so "010" is 10 when it is matched as well,
+// reflect.Invalid is returned for nil, empty string and unsupported values
+func inferKind(value interface{}) reflect.Kind {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return reflect.Invalid
+		}
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return reflect.Int64
+		}
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return reflect.Float64
+		}
+		return reflect.String
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return reflect.Int64
+		}
+		return reflect.Float64
+	case float32, float64:
+		if f := reflect.ValueOf(v).Float(); f == float64(int64(f)) {
+			return reflect.Int64
+		}
+		return reflect.Float64
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return reflect.Int64
+	case bool:
+		return reflect.String
+	}
+	return reflect.Invalid
+}
+
+// InferSchema infer the schema of sample rows, a column is int64, float64 or string,
+// numbers allow lt/lte/eq/gte/gt/neq/in and strings allow contain/notcontain/eq/neq/in/prefix/fuzzy/regex,
+// the columns without any value are skipped and the fields are sorted by name
+func InferSchema(rows []map[string]interface{}) *Schema {
+	kinds := make(map[string]reflect.Kind)
+	for _, row := range rows {
+		for name, value := range row {
+			kind := inferKind(value)
+			if kind == reflect.Invalid {
+				continue
+			}
+			// int64 < float64 < string
+			if old, ok := kinds[name]; !ok || old == reflect.Int64 || (old == reflect.Float64 && kind == reflect.String) {
+				kinds[name] = kind
+			}
+		}
+	}
+	schema := &Schema{}
+	for name, kind := range kinds {
+		field := SchemaField{Name: name, Type: kind.String()}
+		if kind == reflect.String {
+			field.Operators = []string{"contain", "notcontain", "eq", "neq", "in", "prefix", "fuzzy", "regex"}
+		} else {
+			field.Operators = []string{"lt", "lte", "eq", "gte", "gt", "neq", "in"}
+		}
+		schema.Fields = append(schema.Fields, field)
+	}
+	sort.Slice(schema.Fields, func(i, j int) bool { return schema.Fields[i].Name < schema.Fields[j].Name })
+	return schema
+}
@@ -0,0 +1,96 @@
+// Relevance scoring
+// Every searcher contributes a score to the data it matches,
+// exact match > prefix > contain > fuzzy, weighted by the field's boost,
+// the strings are compared ignoring case like fuzzy, so a fuzzy hit never outranks a prefix hit differing only in case
+
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	scoreExact   = 1.0
+	scorePrefix  = 0.75
+	scoreContain = 0.5
+	scoreFuzzy   = 0.25
+)
+
+// fuzzyContains check whether all the characters of sub are in str in order, ignore case
+func fuzzyContains(str, sub string) bool {
+	for _, r := range sub {
+		found := false
+		for len(str) > 0 {
+			c, size := utf8.DecodeRuneInString(str)
+			str = str[size:]
+			if equalFoldRune(c, r) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// stringScore how well value matches str, ignore case
+func stringScore(str, value string) float64 {
+	switch {
+	case strings.EqualFold(str, value):
+		return scoreExact
+	case value == "" || prefixFold(str, value) > 0:
+		return scorePrefix
+	case len(indexFold(str, value)) > 0:
+		return scoreContain
+	case fuzzyContains(str, value):
+		return scoreFuzzy
+	}
+	return 0
+}
+
+// score the score of the data matched by the valid searcher,
+// eq/in score as exact match, contain/prefix/fuzzy score by how well the string matches,
+// match/regex score as contain, other operators are only filters and score 0
+func (s *Searcher) score(in interface{}) float64 {
+	switch s.SearchOperator {
+	case SEARCH_OPERATOR_EQUAL, SEARCH_OPERATOR_IN:
+		return scoreExact
+	case SEARCH_OPERATOR_CONTAIN_OR, SEARCH_OPERATOR_PREFIX, SEARCH_OPERATOR_FUZZY:
+		return stringScore(stringValue(fieldAt(in, s.fieldInfo)), s.value.(string))
+	case SEARCH_OPERATOR_MATCH, SEARCH_OPERATOR_REGEX:
+		return scoreContain
+	}
+	return 0
+}
+
+// Rank return the datas which meet all the searchers with their scores,
+// the score of a data is the sum of every searcher's score multiplied by the field's boost,
+// hits are sorted by score from high to low and then by input order
+func (s *SearcherLimit) Rank(searchers []*Searcher, datasIn []interface{}) (hits []*Hit, err error) {
+	if searchers, err = s.compile(searchers); err != nil {
+		return nil, err
+	}
+	for i, data := range datasIn {
+		if err = s.checkData(data, i); err != nil {
+			return nil, err
+		}
+		var score float64
+		matched := true
+		for _, searcher := range searchers {
+			if !searcher.match(data) {
+				matched = false
+				break
+			}
+			score += s.limit[searcher.Field].Boost * searcher.score(data)
+		}
+		if matched {
+			hits = append(hits, &Hit{Data: data, Score: score, Highlights: s.highlightData(searchers, data)})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	return hits, nil
+}
@@ -7,26 +7,34 @@ package search
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
-	"unsafe"
 
 	"github.com/spf13/cast"
 	"golang.org/x/exp/constraints"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 type SearchOperator int32
 
 const (
-	SEARCH_OPERATOR_UNKNOW        SearchOperator = 0 // not use
-	SEARCH_OPERATOR_CONTAIN_OR    SearchOperator = 1 // contain(fuzzy search)
-	SEARCH_OPERATOR_LESS          SearchOperator = 2 // less than
-	SEARCH_OPERATOR_LESS_EQUAL    SearchOperator = 3 // less than or equal
-	SEARCH_OPERATOR_EQUAL         SearchOperator = 4 // equal
-	SEARCH_OPERATOR_GREATER_EQUAL SearchOperator = 5 // greater than or equal
-	SEARCH_OPERATOR_GREATER       SearchOperator = 6 // greater than
-	SEARCH_OPERATOR_NOT_EQUAL     SearchOperator = 7 // not equal
-	SEARCH_OPERATOR_NOT_CONTAIN   SearchOperator = 8 // not contain
+	SEARCH_OPERATOR_UNKNOW        SearchOperator = 0  // not use
+	SEARCH_OPERATOR_CONTAIN_OR    SearchOperator = 1  // contain(fuzzy search)
+	SEARCH_OPERATOR_LESS          SearchOperator = 2  // less than
+	SEARCH_OPERATOR_LESS_EQUAL    SearchOperator = 3  // less than or equal
+	SEARCH_OPERATOR_EQUAL         SearchOperator = 4  // equal
+	SEARCH_OPERATOR_GREATER_EQUAL SearchOperator = 5  // greater than or equal
+	SEARCH_OPERATOR_GREATER       SearchOperator = 6  // greater than
+	SEARCH_OPERATOR_NOT_EQUAL     SearchOperator = 7  // not equal
+	SEARCH_OPERATOR_NOT_CONTAIN   SearchOperator = 8  // not contain
+	SEARCH_OPERATOR_IN            SearchOperator = 9  // in(value is a comma separated list)
+	SEARCH_OPERATOR_MATCH         SearchOperator = 10 // full-text match(all terms of value)
+	SEARCH_OPERATOR_PREFIX        SearchOperator = 11 // has prefix
+	SEARCH_OPERATOR_FUZZY         SearchOperator = 12 // fuzzy(all characters of value in order, ignore case)
+	SEARCH_OPERATOR_REGEX         SearchOperator = 13 // regular expression
 )
 
 var searchOperatorMap map[string]SearchOperator
@@ -44,6 +52,11 @@ func init() {
 	searchOperatorMap["neq"] = SEARCH_OPERATOR_NOT_EQUAL
 	searchOperatorMap["nc"] = SEARCH_OPERATOR_NOT_CONTAIN
 	searchOperatorMap["notcontain"] = SEARCH_OPERATOR_NOT_CONTAIN
+	searchOperatorMap["in"] = SEARCH_OPERATOR_IN
+	searchOperatorMap["match"] = SEARCH_OPERATOR_MATCH
+	searchOperatorMap["prefix"] = SEARCH_OPERATOR_PREFIX
+	searchOperatorMap["fuzzy"] = SEARCH_OPERATOR_FUZZY
+	searchOperatorMap["regex"] = SEARCH_OPERATOR_REGEX
 	searchOperatorName = []string{
 		"unknow",
 		"contain",
@@ -54,6 +67,11 @@ func init() {
 		"greater",
 		"not equal",
 		"not contain",
+		"in",
+		"match",
+		"prefix",
+		"fuzzy",
+		"regex",
 	}
 }
 
@@ -64,6 +82,8 @@ type searchLimit struct {
 	// Error error message
 	// this error will be thrown when using validCheck to check that an operator is invalid
 	Error error
+	// Boost weight of the field's score when ranking, declared by the boost tag
+	Boost float64
 }
 
 type Searcher struct {
@@ -71,19 +91,29 @@ type Searcher struct {
 	Value          string         // the value of field
 	SearchOperator SearchOperator // search operator
 	fieldKind      reflect.Kind   // kind of field's type
-	offset         uintptr        // offset of field's in struct
+	fieldInfo      *fieldInfo     // cached information of field
 	value          interface{}    // filter value for match
+	tokenizer      Tokenizer      // tokenizer of field for full-text match
 }
 
-type intface struct {
-	typ   unsafe.Pointer
-	value unsafe.Pointer
+// fieldInfo cached information of a searchable field
+type fieldInfo struct {
+	index     int                            // index of field in struct
+	offset    uintptr                        // offset of field in struct
+	kind      reflect.Kind                   // kind of field's type
+	column    string                         // column name in database, declared by db or gorm tag
+	bson      string                         // field name in mongodb, declared by bson tag
+	protoPath []protoreflect.FieldDescriptor // path of protobuf fields from the root message
 }
 
 type SearcherLimit struct {
 	limit            map[string]*searchLimit
-	structType       unsafe.Pointer // save struct's type
-	fieldIndexMap    map[string]int // save field's offset in struct
+	structType       typeKey                        // save struct's type
+	fieldIndexMap    map[string]int                 // save field's offset in struct
+	fieldInfoMap     map[string]*fieldInfo          // save field's offset and kind, key is json tag
+	updateInfoMap    map[string]*fieldInfo          // the fields tagged update:"true", key is json tag
+	tokenizerMap     map[string]Tokenizer           // tokenizer of the fields which support match
+	messageDesc      protoreflect.MessageDescriptor // save message's descriptor, only for limit of message
 	defaultStructVar interface{}
 }
 
@@ -96,17 +126,21 @@ func getSearchOperator(
 		return 0, fmt.Errorf("not support search type(%s)", searchOperatorStr)
 	}
 	if (fieldKind >= reflect.Int && fieldKind <= reflect.Uint64) ||
-		fieldKind == reflect.Float32 || fieldKind == reflect.Float64 { // Only </<=/=/>=/>/!= is allowed for numeric type
+		fieldKind == reflect.Float32 || fieldKind == reflect.Float64 { // Only </<=/=/>=/>/!=/in is allowed for numeric type
 		if s != SEARCH_OPERATOR_LESS && s != SEARCH_OPERATOR_LESS_EQUAL &&
 			s != SEARCH_OPERATOR_EQUAL && s != SEARCH_OPERATOR_GREATER_EQUAL &&
-			s != SEARCH_OPERATOR_GREATER && s != SEARCH_OPERATOR_NOT_EQUAL {
+			s != SEARCH_OPERATOR_GREATER && s != SEARCH_OPERATOR_NOT_EQUAL &&
+			s != SEARCH_OPERATOR_IN {
 			return 0, fmt.Errorf("field(%s) is number type, not support search type(%s)", jsonTag, searchOperatorStr)
 		}
 		return s, nil // Record the currently allowed search operators
 	}
-	if fieldKind == reflect.String { // only contain/=/!=/not contain is allowed for string type
+	if fieldKind == reflect.String { // only contain/=/!=/not contain/in/match/prefix/fuzzy/regex is allowed for string type
 		if s != SEARCH_OPERATOR_CONTAIN_OR && s != SEARCH_OPERATOR_EQUAL &&
-			s != SEARCH_OPERATOR_NOT_EQUAL && s != SEARCH_OPERATOR_NOT_CONTAIN {
+			s != SEARCH_OPERATOR_NOT_EQUAL && s != SEARCH_OPERATOR_NOT_CONTAIN &&
+			s != SEARCH_OPERATOR_IN && s != SEARCH_OPERATOR_MATCH &&
+			s != SEARCH_OPERATOR_PREFIX && s != SEARCH_OPERATOR_FUZZY &&
+			s != SEARCH_OPERATOR_REGEX {
 			return 0, fmt.Errorf("field(%s) is string type, not support search type(%s)", jsonTag, searchOperatorStr)
 		}
 		return s, nil // Record the currently allowed search operators
@@ -117,6 +151,73 @@ func getSearchOperator(
 	return SEARCH_OPERATOR_UNKNOW, nil // numeric
 }
 
+// getColumnName get the column name of field from db tag or gorm's column tag,
+// json tag is used if neither is declared
+func getColumnName(tag reflect.StructTag, jsonTag string) string {
+	if column, _, _ := strings.Cut(tag.Get("db"), ","); column != "" && column != "-" {
+		return column
+	}
+	for _, setting := range strings.Split(tag.Get("gorm"), ";") {
+		if name, value, ok := strings.Cut(strings.TrimSpace(setting), ":"); ok &&
+			strings.EqualFold(name, "column") && value != "" {
+			return value
+		}
+	}
+	return jsonTag
+}
+
+// getBsonName get the field name in mongodb from bson tag, json tag is used if it is not declared
+func getBsonName(tag reflect.StructTag, jsonTag string) string {
+	if name, _, _ := strings.Cut(tag.Get("bson"), ","); name != "" && name != "-" {
+		return name
+	}
+	return jsonTag
+}
+
+// newSearchLimit parse the search operators, boost and tokenizer of a field,
+// tokenizer is nil if the field does not support match
+func newSearchLimit(
+	fKind reflect.Kind, name string, searchTag string, boostTag string, tokenizerTag string,
+) (sLimit *searchLimit, tokenizer Tokenizer, err error) {
+	sLimit = &searchLimit{Boost: 1}
+	if boostTag != "" {
+		boost, err := cast.ToFloat64E(boostTag)
+		if err != nil || boost < 0 {
+			return nil, nil, fmt.Errorf("field(%s) has invalid boost(%s)", name, boostTag)
+		}
+		sLimit.Boost = boost
+	}
+	searchOperatorStrs := strings.Split(searchTag, ",")
+	searchOperatorDuplicateMap := make(map[string]bool)
+	for _, sStr := range searchOperatorStrs {
+		sStr = strings.TrimSpace(sStr)
+		if _, ok := searchOperatorDuplicateMap[sStr]; ok { // duplication search operator
+			continue
+		}
+		searchOperatorDuplicateMap[sStr] = true
+		s, err := getSearchOperator(fKind, sStr, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if s != SEARCH_OPERATOR_UNKNOW {
+			sLimit.SearchOperators = append(sLimit.SearchOperators, s)
+		}
+		if s == SEARCH_OPERATOR_MATCH {
+			if tokenizer, err = newTokenizer(tokenizerTag); err != nil {
+				return nil, nil, fmt.Errorf("field(%s) %s", name, err.Error())
+			}
+		}
+	}
+	sStrNew := make([]string, len(sLimit.SearchOperators))
+	for k, s := range sLimit.SearchOperators {
+		sStrNew[k] = searchOperatorName[s]
+	}
+	// full error msg
+	sLimit.Error = fmt.Errorf("field(%s) only support search operate: %s",
+		name, strings.Join(sStrNew, "/"))
+	return sLimit, tokenizer, nil
+}
+
 // NewSearcherLimit Construct a searcher for structure search and judgment
 func NewSearcherLimit(i interface{}) (*SearcherLimit, error) {
 	t := reflect.TypeOf(i)
@@ -128,11 +229,21 @@ func NewSearcherLimit(i interface{}) (*SearcherLimit, error) {
 	if t.Kind() != reflect.Struct {
 		return nil, errors.New("param i must be a struct or a pointer of struct")
 	}
-	structType := (*intface)(unsafe.Pointer(&i)).typ
+	structType := typeOf(i)
 	limit := make(map[string]*searchLimit)
 	fieldIndexMap := make(map[string]int)
+	fieldInfoMap := make(map[string]*fieldInfo)
+	tokenizerMap := make(map[string]Tokenizer)
+	updateInfoMap := make(map[string]*fieldInfo)
 	for i := 0; i < t.NumField(); i++ {
 		tag := t.Field(i).Tag
+		updateInfo, err := newUpdateInfo(t.Field(i))
+		if err != nil {
+			return nil, err
+		}
+		if updateInfo != nil {
+			updateInfoMap[tag.Get("json")] = updateInfo
+		}
 		searchTag := tag.Get("search")
 		if searchTag == "" {
 			continue
@@ -141,111 +252,201 @@ func NewSearcherLimit(i interface{}) (*SearcherLimit, error) {
 		if jsonTag == "" {
 			continue
 		}
-		sLimit := &searchLimit{}
-		searchOperatorStrs := strings.Split(searchTag, ",")
-		searchOperatorDuplicateMap := make(map[string]bool)
 		fKind := v.Field(i).Type().Kind()
-		for _, sStr := range searchOperatorStrs {
-			sStr = strings.TrimSpace(sStr)
-			if _, ok := searchOperatorDuplicateMap[sStr]; ok { // duplication search operator
-				continue
-			}
-			searchOperatorDuplicateMap[sStr] = true
-			s, err := getSearchOperator(fKind, sStr, jsonTag)
-			if err != nil {
-				return nil, err
-			}
-			if s != SEARCH_OPERATOR_UNKNOW {
-				sLimit.SearchOperators = append(sLimit.SearchOperators, s)
-			}
+		sLimit, tokenizer, err := newSearchLimit(fKind, jsonTag, searchTag, tag.Get("boost"), tag.Get("tokenizer"))
+		if err != nil {
+			return nil, err
 		}
-		sStrNew := make([]string, len(sLimit.SearchOperators))
-		for k, s := range sLimit.SearchOperators {
-			sStrNew[k] = searchOperatorName[s]
+		if tokenizer != nil {
+			tokenizerMap[jsonTag] = tokenizer
 		}
-		// full error msg
-		sLimit.Error = fmt.Errorf("field(%s) only support search operate: %s",
-			jsonTag, strings.Join(sStrNew, "/"))
 		limit[jsonTag] = sLimit
 		fieldIndexMap[jsonTag] = i
+		fieldInfoMap[jsonTag] = &fieldInfo{
+			index: i, offset: t.Field(i).Offset, kind: fKind,
+			column: getColumnName(tag, jsonTag), bson: getBsonName(tag, jsonTag),
+		}
 	}
 	return &SearcherLimit{
 		limit:            limit,
 		structType:       structType,
 		fieldIndexMap:    fieldIndexMap,
+		fieldInfoMap:     fieldInfoMap,
+		updateInfoMap:    updateInfoMap,
+		tokenizerMap:     tokenizerMap,
 		defaultStructVar: i,
 	}, nil
 }
 
-// getFieldOffsetAndType get field's type and offset
-func (s *Searcher) getFieldOffsetAndType(
-	in interface{}, limit *SearcherLimit, fieldIndex int,
-) (err error) {
-	intf := (*intface)(unsafe.Pointer(&in))
-	if intf.typ != limit.structType {
-		return fmt.Errorf("in's type is invalid")
-	}
-	s.fieldKind = reflect.ValueOf(in).Elem().Field(fieldIndex).Kind()
-	// the offset of in.(s.Field)
-	s.offset = reflect.TypeOf(in).Elem().Field(fieldIndex).Offset
-	return
+// SetTokenizer return a copy of the limit with the tokenizer of a field which supports match replaced,
+// s is not modified, so a limit shared by LimitFor is never changed by a caller
+func (s *SearcherLimit) SetTokenizer(field string, tokenizer Tokenizer) (*SearcherLimit, error) {
+	if _, ok := s.tokenizerMap[field]; !ok {
+		return nil, fmt.Errorf("field(%s) does not support match", field)
+	}
+	if tokenizer == nil {
+		return nil, fmt.Errorf("tokenizer of field(%s) is nil", field)
+	}
+	limit := *s
+	limit.tokenizerMap = make(map[string]Tokenizer, len(s.tokenizerMap))
+	for name, t := range s.tokenizerMap {
+		limit.tokenizerMap[name] = t
+	}
+	limit.tokenizerMap[field] = tokenizer
+	return &limit, nil
 }
 
 // getFilterValue Converts the value (string) used as a search
 // to a value of the corresponding type
 func (s *Searcher) genFilterValue() {
-	switch s.fieldKind {
+	if s.SearchOperator == SEARCH_OPERATOR_IN {
+		strs := strings.Split(s.Value, ",")
+		values := make([]interface{}, len(strs))
+		for k, str := range strs {
+			values[k] = castValue(strings.TrimSpace(str), s.fieldKind)
+		}
+		s.value = values
+		return
+	}
+	s.value = castValue(s.Value, s.fieldKind)
+}
+
+// castValue Converts the string value to a value of kind
+func castValue(value string, kind reflect.Kind) interface{} {
+	v, _ := castValueE(value, kind)
+	return v
+}
+
+// intBitSizes bit size of the integer kinds for strconv, 0 means the size of int
+var intBitSizes = map[reflect.Kind]int{
+	reflect.Int8: 8, reflect.Int16: 16, reflect.Int32: 32, reflect.Int64: 64,
+	reflect.Uint8: 8, reflect.Uint16: 16, reflect.Uint32: 32, reflect.Uint64: 64,
+}
+
+// parseDecimal parse the string as a decimal integer of kind, cast parses in base 0 so "010" would be octal 8,
+// a float text of an integer like 3.0 or 1e2 is returned as float64, the same as a number decoded from json
+func parseDecimal(str string, kind reflect.Kind) (interface{}, error) {
+	var n interface{}
+	var err error
+	if kind >= reflect.Uint {
+		n, err = strconv.ParseUint(str, 10, intBitSizes[kind])
+	} else {
+		n, err = strconv.ParseInt(str, 10, intBitSizes[kind])
+	}
+	if err == nil {
+		return n, nil
+	}
+	f, ferr := strconv.ParseFloat(str, 64)
+	if ferr != nil || f != math.Trunc(f) || math.IsInf(f, 0) {
+		return nil, err
+	}
+	return f, nil
+}
+
+// castValueE Converts the value to a value of kind,
+// a zero value of kind and an error are returned if it can not be converted.
+// Strings are parsed as decimal integers for the integer kinds, see parseDecimal,
+// and floats with fractions are not truncated to integers like cast, e.g. 3.5 is not 3
+func castValueE(value interface{}, kind reflect.Kind) (interface{}, error) {
+	if kind >= reflect.Int && kind <= reflect.Uint64 {
+		switch v := value.(type) {
+		case string:
+			n, err := parseDecimal(v, kind)
+			if err != nil {
+				zero, _ := castValueE(0, kind)
+				return zero, fmt.Errorf("unable to cast %q of type string to %s", v, kind)
+			}
+			value = n
+		case float32, float64:
+			if f := reflect.ValueOf(v).Float(); f != math.Trunc(f) {
+				zero, _ := castValueE(0, kind)
+				return zero, fmt.Errorf("unable to cast %v of type %T to %s", v, v, kind)
+			}
+		}
+	}
+	switch kind {
 	case reflect.Int:
-		s.value = cast.ToInt(s.Value)
+		return cast.ToIntE(value)
 	case reflect.Int8:
-		s.value = cast.ToInt8(s.Value)
+		return cast.ToInt8E(value)
 	case reflect.Int16:
-		s.value = cast.ToInt16(s.Value)
+		return cast.ToInt16E(value)
 	case reflect.Int32:
-		s.value = cast.ToInt32(s.Value)
+		return cast.ToInt32E(value)
 	case reflect.Int64:
-		s.value = cast.ToInt64(s.Value)
+		return cast.ToInt64E(value)
 	case reflect.Uint:
-		s.value = cast.ToUint(s.Value)
+		return cast.ToUintE(value)
 	case reflect.Uint8:
-		s.value = cast.ToUint8(s.Value)
+		return cast.ToUint8E(value)
 	case reflect.Uint16:
-		s.value = cast.ToUint16(s.Value)
+		return cast.ToUint16E(value)
 	case reflect.Uint32:
-		s.value = cast.ToUint32(s.Value)
+		return cast.ToUint32E(value)
 	case reflect.Uint64:
-		s.value = cast.ToUint64(s.Value)
+		return cast.ToUint64E(value)
 	case reflect.Float32:
-		s.value = cast.ToFloat32(s.Value)
+		return cast.ToFloat32E(value)
 	case reflect.Float64:
-		s.value = cast.ToFloat64(s.Value)
+		return cast.ToFloat64E(value)
 	case reflect.String:
-		s.value = s.Value
+		return cast.ToStringE(value)
 	}
+	return nil, fmt.Errorf("not support kind(%s)", kind)
 }
 
-// ValidCheck Search operator validity check
+// ValidCheck Search operator validity check, the compiled state is stored in infos for Filter,
+// use Compile when the searchers are shared between goroutines or limits
 func (s *SearcherLimit) ValidCheck(infos []*Searcher) (err error) {
 	for k, info := range infos {
-		searchLimit, ok := s.limit[info.Field]
-		if !ok {
-			return fmt.Errorf("field(%s) does not support search", info.Field)
-		}
-		invalid := true
-		for _, so := range searchLimit.SearchOperators {
-			if so == info.SearchOperator { // info.SearchOperator is valid
-				invalid = false
-				break
-			}
+		if err = s.validCheck(k, info); err != nil {
+			return err
 		}
-		if invalid { // invalid message
-			return fmt.Errorf("searchers[%d] is invalid, %s", k, searchLimit.Error)
+	}
+	return nil
+}
+
+// validCheck check the k-th searcher and prepare its value for matching
+func (s *SearcherLimit) validCheck(k int, info *Searcher) error {
+	reason := s.checkSearcher(info)
+	if reason == nil {
+		return nil
+	}
+	if _, ok := s.limit[info.Field]; !ok { // an unknown field is reported without the index
+		return reason
+	}
+	return fmt.Errorf("searchers[%d] is invalid, %s", k, reason.Error())
+}
+
+// checkSearcher check the searcher and prepare its value for matching, the error is the bare reason
+func (s *SearcherLimit) checkSearcher(info *Searcher) error {
+	searchLimit, ok := s.limit[info.Field]
+	if !ok {
+		return fmt.Errorf("field(%s) does not support search", info.Field)
+	}
+	invalid := true
+	for _, so := range searchLimit.SearchOperators {
+		if so == info.SearchOperator { // info.SearchOperator is valid
+			invalid = false
+			break
 		}
-		err = info.getFieldOffsetAndType(s.defaultStructVar, s, s.fieldIndexMap[info.Field])
+	}
+	if invalid { // invalid message
+		return searchLimit.Error
+	}
+	fInfo := s.fieldInfoMap[info.Field]
+	info.fieldKind, info.fieldInfo = fInfo.kind, fInfo
+	info.genFilterValue()
+	if info.SearchOperator == SEARCH_OPERATOR_MATCH {
+		info.tokenizer = s.tokenizerMap[info.Field]
+		info.value = uniqueTerms(info.tokenizer.Tokenize(info.Value))
+	}
+	if info.SearchOperator == SEARCH_OPERATOR_REGEX {
+		re, err := regexp.Compile(info.Value)
 		if err != nil {
 			return err
 		}
-		info.genFilterValue()
+		info.value = re
 	}
 	return nil
 }
@@ -266,7 +467,7 @@ func gteCompare[K1 constraints.Ordered](left, right K1) bool { // 泛型函数
 	return left >= right
 }
 func gtCompare[K1 constraints.Ordered](left, right K1) bool { // 泛型函数
-	return left != right
+	return left > right
 }
 func neqCompare[K1 constraints.Ordered](left, right K1) bool { // 泛型函数
 	return left != right
@@ -274,6 +475,12 @@ func neqCompare[K1 constraints.Ordered](left, right K1) bool { // 泛型函数
 func notContainCompare[K1 ~string](left, right K1) bool { // 泛型函数
 	return !strings.Contains(string(left), string(right))
 }
+func prefixCompare[K1 ~string](left, right K1) bool { // 泛型函数
+	return strings.HasPrefix(string(left), string(right))
+}
+func fuzzyCompare[K1 ~string](left, right K1) bool { // 泛型函数
+	return fuzzyContains(string(left), string(right))
+}
 
 // doNumbericMatch numberic match
 func doNumbericMatch[K1 constraints.Integer | constraints.Float](
@@ -306,40 +513,70 @@ func doStringMatch[K1 ~string](left, right K1, searchOperator SearchOperator) bo
 		return neqCompare(left, right)
 	case SEARCH_OPERATOR_NOT_CONTAIN:
 		return notContainCompare(left, right)
+	case SEARCH_OPERATOR_PREFIX:
+		return prefixCompare(left, right)
+	case SEARCH_OPERATOR_FUZZY:
+		return fuzzyCompare(left, right)
 	}
 	return false
 }
 
-// match Check whether the data meets the search condition
-func (s *Searcher) match(in interface{}) bool {
-	dataPtr := unsafe.Pointer(uintptr((*intface)(unsafe.Pointer(&in)).value) + s.offset)
-	switch s.fieldKind {
-	case reflect.Int:
-		return doNumbericMatch(*(*int)(dataPtr), s.value.(int), s.SearchOperator)
-	case reflect.Int8:
-		return doNumbericMatch(*(*int8)(dataPtr), s.value.(int8), s.SearchOperator)
-	case reflect.Int16:
-		return doNumbericMatch(*(*int16)(dataPtr), s.value.(int16), s.SearchOperator)
-	case reflect.Int32:
-		return doNumbericMatch(*(*int32)(dataPtr), s.value.(int32), s.SearchOperator)
-	case reflect.Int64:
-		return doNumbericMatch(*(*int64)(dataPtr), s.value.(int64), s.SearchOperator)
-	case reflect.Uint:
-		return doNumbericMatch(*(*uint)(dataPtr), s.value.(uint), s.SearchOperator)
-	case reflect.Uint8:
-		return doNumbericMatch(*(*uint8)(dataPtr), s.value.(uint8), s.SearchOperator)
-	case reflect.Uint16:
-		return doNumbericMatch(*(*uint16)(dataPtr), s.value.(uint16), s.SearchOperator)
-	case reflect.Uint32:
-		return doNumbericMatch(*(*uint32)(dataPtr), s.value.(uint32), s.SearchOperator)
-	case reflect.Uint64:
-		return doNumbericMatch(*(*uint64)(dataPtr), s.value.(uint64), s.SearchOperator)
-	case reflect.Float32:
-		return doNumbericMatch(*(*float32)(dataPtr), s.value.(float32), s.SearchOperator)
-	case reflect.Float64:
-		return doNumbericMatch(*(*float64)(dataPtr), s.value.(float64), s.SearchOperator)
-	case reflect.String:
-		return doStringMatch(*(*string)(dataPtr), s.value.(string), s.SearchOperator)
+// matchValue Check whether the value meets the search condition,
+// value is converted to the kind of field first, it does not match if it can not be converted
+func (s *Searcher) matchValue(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	v, err := castValueE(value, s.fieldKind)
+	if err != nil {
+		return false
+	}
+	return s.matchTyped(v)
+}
+
+// matchTyped Check whether the value of the field's kind meets the search condition
+func (s *Searcher) matchTyped(v interface{}) bool {
+	if s.SearchOperator == SEARCH_OPERATOR_IN {
+		for _, value := range s.value.([]interface{}) {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}
+	switch v := v.(type) {
+	case int:
+		return doNumbericMatch(v, s.value.(int), s.SearchOperator)
+	case int8:
+		return doNumbericMatch(v, s.value.(int8), s.SearchOperator)
+	case int16:
+		return doNumbericMatch(v, s.value.(int16), s.SearchOperator)
+	case int32:
+		return doNumbericMatch(v, s.value.(int32), s.SearchOperator)
+	case int64:
+		return doNumbericMatch(v, s.value.(int64), s.SearchOperator)
+	case uint:
+		return doNumbericMatch(v, s.value.(uint), s.SearchOperator)
+	case uint8:
+		return doNumbericMatch(v, s.value.(uint8), s.SearchOperator)
+	case uint16:
+		return doNumbericMatch(v, s.value.(uint16), s.SearchOperator)
+	case uint32:
+		return doNumbericMatch(v, s.value.(uint32), s.SearchOperator)
+	case uint64:
+		return doNumbericMatch(v, s.value.(uint64), s.SearchOperator)
+	case float32:
+		return doNumbericMatch(v, s.value.(float32), s.SearchOperator)
+	case float64:
+		return doNumbericMatch(v, s.value.(float64), s.SearchOperator)
+	case string:
+		switch s.SearchOperator {
+		case SEARCH_OPERATOR_MATCH:
+			return matchTerms(s.tokenizer.Tokenize(v), s.value.([]string))
+		case SEARCH_OPERATOR_REGEX:
+			return s.value.(*regexp.Regexp).MatchString(v)
+		}
+		return doStringMatch(v, s.value.(string), s.SearchOperator)
 	}
 	return false
 }
@@ -352,16 +589,8 @@ func (s *Searcher) Filter(
 		return nil, nil
 	}
 	for i := 0; i < len(datasIn); i++ {
-		if datasIn[0] == nil {
-			return nil, fmt.Errorf("datasIn[%d] is nil", i)
-		}
-		if reflect.ValueOf(datasIn[i]).Pointer() == 0 {
-			return nil, fmt.Errorf("datasIn[%d] is a nil pointer", i)
-		}
-		typ := (*intface)(unsafe.Pointer(&datasIn[i])).typ
-		// Check whether the type of dataIn [i] is the same as that recorded in the parameter limit
-		if typ != limit.structType {
-			return nil, fmt.Errorf("datasIn[%d]'s type is invalid", i)
+		if err = limit.checkData(datasIn[i], i); err != nil {
+			return nil, err
 		}
 		if s.match(datasIn[i]) {
 			datasOut = append(datasOut, datasIn[i])
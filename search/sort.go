@@ -0,0 +1,31 @@
+// Sort datas by a searchable field
+// The generated sorters of searchgen produce the same order
+
+package search
+
+import (
+	"sort"
+)
+
+// Sort sort datas by the value of field stably, from small to large or from large to small if desc
+func (s *SearcherLimit) Sort(datas []interface{}, field string, desc bool) error {
+	infos, err := s.getFieldInfos([]string{field}, "sort")
+	if err != nil {
+		return err
+	}
+	for i, data := range datas {
+		if err = s.checkData(data, i); err != nil {
+			return err
+		}
+	}
+	info := infos[0]
+	sort.SliceStable(datas, func(i, j int) bool {
+		left := fieldValue(fieldAt(datas[i], info), info.kind)
+		cmp := compareValue(left, fieldValue(fieldAt(datas[j], info), info.kind))
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+	return nil
+}
@@ -0,0 +1,185 @@
+// Translate searchers into a parameterized SQL WHERE clause
+// The json names are mapped to column names by db or gorm tag,
+// the searchers are a flat AND like the other backends, OR/NOT grouping has no searcher form and is not translated
+
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Dialect int32
+
+const (
+	DIALECT_MYSQL    Dialect = 1 // placeholder is ?, identifier is quoted by `
+	DIALECT_POSTGRES Dialect = 2 // placeholder is $n, identifier is quoted by "
+	DIALECT_SQLITE   Dialect = 3 // placeholder is ?, identifier is quoted by "
+)
+
+// quoteIdent quote the column name
+func (d Dialect) quoteIdent(name string) string {
+	if d == DIALECT_MYSQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// placeholder the placeholder of the n-th(from 1) argument
+func (d Dialect) placeholder(n int) string {
+	if d == DIALECT_POSTGRES {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// like the LIKE expression, backslash is the default escape character of mysql and postgres,
+// sqlite has no default escape character so it is declared
+func (d Dialect) like(column, placeholder string, not bool) string {
+	op := " LIKE "
+	if not {
+		op = " NOT LIKE "
+	}
+	if d == DIALECT_SQLITE {
+		return column + op + placeholder + ` ESCAPE '\'`
+	}
+	return column + op + placeholder
+}
+
+// instr the case-sensitive contain expression of sqlite, whose LIKE ignores the case of ASCII letters
+func (d Dialect) instr(column, placeholder string, not bool) string {
+	if not {
+		return "instr(" + column + ", " + placeholder + ") = 0"
+	}
+	return "instr(" + column + ", " + placeholder + ") > 0"
+}
+
+// escapeGlob escape the wildcard characters of sqlite GLOB pattern by brackets
+func escapeGlob(value string) string {
+	return strings.NewReplacer(`*`, `[*]`, `?`, `[?]`, `[`, `[[]`).Replace(value)
+}
+
+// escapeLike escape the wildcard characters of LIKE pattern by backslash
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// fuzzyLikePattern a LIKE pattern which matches all the characters of value in order
+func fuzzyLikePattern(value string) string {
+	var b strings.Builder
+	b.WriteByte('%')
+	for _, r := range value {
+		b.WriteString(escapeLike(string(r)))
+		b.WriteByte('%')
+	}
+	return b.String()
+}
+
+// checkSQLRegex check that the go pattern only uses the syntax which mysql(ICU) and postgres(ARE) read the same way,
+// the pattern is passed unchanged, so flags (?i), \b(backspace in postgres), \p{..}, \Q..\E, \A, \z
+// and escaped digits(back references in postgres) are rejected rather than matching other rows than Filter
+func checkSQLRegex(pattern string) error {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			if i+1 < len(pattern) && strings.IndexByte("bBpPQEzAC0123456789", pattern[i+1]) >= 0 {
+				return fmt.Errorf("regex escape \\%c is not supported", pattern[i+1])
+			}
+			i++
+		case '(':
+			if i+1 < len(pattern) && pattern[i+1] == '?' {
+				return fmt.Errorf("regex flags and named groups are not supported")
+			}
+		}
+	}
+	return nil
+}
+
+// ToSQL translate the searchers into a WHERE clause(without the WHERE keyword) and its arguments,
+// the searchers are combined with AND, an empty clause means no condition.
+// contain/notcontain/prefix are case-sensitive like Filter:
+// sqlite uses instr and GLOB, postgres LIKE is case-sensitive, mysql LIKE and REGEXP depend on the collation,
+// so a case-sensitive(e.g. utf8mb4_bin) column is required for the same results,
+// fuzzy ignores case and is translated to LIKE(ILIKE of postgres),
+// regex is supported by mysql(REGEXP) and postgres(~) for the syntax shared with go, see checkSQLRegex,
+// match is not supported
+func (s *SearcherLimit) ToSQL(
+	searchers []*Searcher, dialect Dialect,
+) (where string, args []interface{}, err error) {
+	if dialect != DIALECT_MYSQL && dialect != DIALECT_POSTGRES && dialect != DIALECT_SQLITE {
+		return "", nil, fmt.Errorf("not support dialect(%d)", dialect)
+	}
+	if searchers, err = s.compile(searchers); err != nil {
+		return "", nil, err
+	}
+	conds := make([]string, 0, len(searchers))
+	next := func(arg interface{}) string {
+		args = append(args, arg)
+		return dialect.placeholder(len(args))
+	}
+	for k, searcher := range searchers {
+		column := dialect.quoteIdent(s.fieldInfoMap[searcher.Field].column)
+		var cond string
+		switch searcher.SearchOperator {
+		case SEARCH_OPERATOR_LESS:
+			cond = column + " < " + next(searcher.value)
+		case SEARCH_OPERATOR_LESS_EQUAL:
+			cond = column + " <= " + next(searcher.value)
+		case SEARCH_OPERATOR_EQUAL:
+			cond = column + " = " + next(searcher.value)
+		case SEARCH_OPERATOR_GREATER_EQUAL:
+			cond = column + " >= " + next(searcher.value)
+		case SEARCH_OPERATOR_GREATER:
+			cond = column + " > " + next(searcher.value)
+		case SEARCH_OPERATOR_NOT_EQUAL:
+			cond = column + " <> " + next(searcher.value)
+		case SEARCH_OPERATOR_IN:
+			values := searcher.value.([]interface{})
+			placeholders := make([]string, len(values))
+			for i, value := range values {
+				placeholders[i] = next(value)
+			}
+			cond = column + " IN (" + strings.Join(placeholders, ", ") + ")"
+		case SEARCH_OPERATOR_CONTAIN_OR, SEARCH_OPERATOR_NOT_CONTAIN:
+			not := searcher.SearchOperator == SEARCH_OPERATOR_NOT_CONTAIN
+			if dialect == DIALECT_SQLITE {
+				cond = dialect.instr(column, next(searcher.Value), not)
+			} else {
+				cond = dialect.like(column, next("%"+escapeLike(searcher.Value)+"%"), not)
+			}
+		case SEARCH_OPERATOR_PREFIX:
+			if dialect == DIALECT_SQLITE {
+				cond = column + " GLOB " + next(escapeGlob(searcher.Value)+"*")
+			} else {
+				cond = dialect.like(column, next(escapeLike(searcher.Value)+"%"), false)
+			}
+		case SEARCH_OPERATOR_FUZZY:
+			if dialect == DIALECT_POSTGRES {
+				cond = column + " ILIKE " + next(fuzzyLikePattern(searcher.Value))
+			} else {
+				cond = dialect.like(column, next(fuzzyLikePattern(searcher.Value)), false)
+			}
+		case SEARCH_OPERATOR_REGEX:
+			pattern := searcher.value.(*regexp.Regexp).String()
+			if dialect == DIALECT_SQLITE {
+				break
+			}
+			if err = checkSQLRegex(pattern); err != nil {
+				return "", nil, fmt.Errorf("searchers[%d] can not be translated to sql, %s", k, err.Error())
+			}
+			if dialect == DIALECT_MYSQL {
+				cond = column + " REGEXP " + next(pattern)
+			} else if dialect == DIALECT_POSTGRES {
+				cond = column + " ~ " + next(pattern)
+			}
+		}
+		if cond == "" {
+			return "", nil, fmt.Errorf("searchers[%d] can not be translated to sql, %s is not supported",
+				k, searchOperatorName[searcher.SearchOperator])
+		}
+		conds = append(conds, cond)
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
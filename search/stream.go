@@ -0,0 +1,103 @@
+// Streaming filter
+// The datas come from a channel or an iterator instead of a slice,
+// so the filtered datas are delivered one by one without materializing the output
+
+package search
+
+import (
+	"context"
+)
+
+// Seq an iterator which calls yield for each data until yield returns false,
+// it has the same shape as iter.Seq[interface{}]
+type Seq func(yield func(data interface{}) bool)
+
+// SliceSeq the iterator of datas
+func SliceSeq(datas []interface{}) Seq {
+	return func(yield func(data interface{}) bool) {
+		for _, data := range datas {
+			if !yield(data) {
+				return
+			}
+		}
+	}
+}
+
+// matchAll check whether data meets all the valid searchers
+func matchAll(searchers []*Searcher, data interface{}) bool {
+	for _, searcher := range searchers {
+		if !searcher.match(data) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterSeq call yield for each data of seq which meets all the searchers,
+// it stops early when yield returns false or a data is invalid,
+// the index of an invalid data in the error is its position in seq
+func (s *SearcherLimit) FilterSeq(searchers []*Searcher, seq Seq, yield func(data interface{}) bool) (err error) {
+	if searchers, err = s.compile(searchers); err != nil {
+		return err
+	}
+	i := 0
+	seq(func(data interface{}) bool {
+		if err = s.checkData(data, i); err != nil {
+			return false
+		}
+		i++
+		if !matchAll(searchers, data) {
+			return true
+		}
+		return yield(data)
+	})
+	return err
+}
+
+// FilterChan filter the datas received from in and send the matched datas to the returned channel,
+// the returned channel is closed when in is closed, ctx is done or a data is invalid.
+// The error channel receives at most one error(the invalid searchers/data or ctx.Err()) and is closed after out
+func (s *SearcherLimit) FilterChan(
+	ctx context.Context, searchers []*Searcher, in <-chan interface{},
+) (<-chan interface{}, <-chan error) {
+	out := make(chan interface{})
+	errc := make(chan error, 1)
+	searchers, err := s.compile(searchers)
+	if err != nil {
+		close(out)
+		errc <- err
+		close(errc)
+		return out, errc
+	}
+	go func() {
+		defer close(errc)
+		defer close(out)
+		for i := 0; ; i++ {
+			var data interface{}
+			var ok bool
+			select {
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			case data, ok = <-in:
+			}
+			if !ok {
+				return
+			}
+			if err := s.checkData(data, i); err != nil {
+				errc <- err
+				return
+			}
+			if !matchAll(searchers, data) {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			case out <- data:
+			}
+		}
+	}()
+	return out, errc
+}
@@ -0,0 +1,217 @@
+// Tokenizers used by the match operator and the inverted index
+
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cast"
+)
+
+// Tokenizer split a text into terms
+type Tokenizer interface {
+	Tokenize(text string) []string
+}
+
+// TokenizerFunc adapt a function to Tokenizer
+type TokenizerFunc func(text string) []string
+
+// Tokenize call f(text)
+func (f TokenizerFunc) Tokenize(text string) []string {
+	return f(text)
+}
+
+// Token a term and where it is in the text, End is exclusive
+type Token struct {
+	Term  string
+	Start int // byte offset of the start
+	End   int // byte offset of the end
+}
+
+// SpanTokenizer a Tokenizer which also reports the positions of the terms, the match highlights
+// are the tokens of the field's tokenizer, all the built-in tokenizers implement it
+type SpanTokenizer interface {
+	Tokenizer
+	TokenizeSpans(text string) []Token
+}
+
+// newToken the token of text[start:end], the term is lower case
+func newToken(text string, start, end int) Token {
+	return Token{Term: strings.ToLower(text[start:end]), Start: start, End: end}
+}
+
+// termsOf the terms of the tokens
+func termsOf(tokens []Token) []string {
+	terms := make([]string, len(tokens))
+	for k, token := range tokens {
+		terms[k] = token.Term
+	}
+	return terms
+}
+
+// WhitespaceTokenizer split text by white space and lower the terms
+type WhitespaceTokenizer struct{}
+
+// Tokenize implement Tokenizer
+func (t WhitespaceTokenizer) Tokenize(text string) []string {
+	return termsOf(t.TokenizeSpans(text))
+}
+
+// TokenizeSpans implement SpanTokenizer
+func (WhitespaceTokenizer) TokenizeSpans(text string) []Token {
+	var tokens []Token
+	start := -1 // start of the current word
+	for i, r := range text {
+		if !unicode.IsSpace(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, newToken(text, start, i))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, newToken(text, start, len(text)))
+	}
+	return tokens
+}
+
+// NGramTokenizer split every word of text into n-grams of runes,
+// a word shorter than N is kept as a term
+type NGramTokenizer struct {
+	N int
+}
+
+// Tokenize implement Tokenizer
+func (t NGramTokenizer) Tokenize(text string) []string {
+	return termsOf(t.TokenizeSpans(text))
+}
+
+// TokenizeSpans implement SpanTokenizer
+func (t NGramTokenizer) TokenizeSpans(text string) []Token {
+	n := t.N
+	if n <= 0 {
+		n = 2
+	}
+	var tokens []Token
+	for _, word := range (WhitespaceTokenizer{}).TokenizeSpans(text) {
+		// lowering maps rune to rune, so the n-grams are located by the runes of the original word
+		var offsets []int
+		for i := range text[word.Start:word.End] {
+			offsets = append(offsets, word.Start+i)
+		}
+		if len(offsets) <= n {
+			tokens = append(tokens, word)
+			continue
+		}
+		offsets = append(offsets, word.End)
+		for i := 0; i+n < len(offsets); i++ {
+			tokens = append(tokens, newToken(text, offsets[i], offsets[i+n]))
+		}
+	}
+	return tokens
+}
+
+// isCJK check whether r is a chinese, japanese or korean character
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// BigramTokenizer split runs of CJK characters into overlapping bigrams
+// and other letters or digits into lower words, punctuation is dropped,
+// e.g. "上海市Go语言" => 上海 海市 go 语言
+type BigramTokenizer struct{}
+
+// Tokenize implement Tokenizer
+func (t BigramTokenizer) Tokenize(text string) []string {
+	return termsOf(t.TokenizeSpans(text))
+}
+
+// TokenizeSpans implement SpanTokenizer
+func (BigramTokenizer) TokenizeSpans(text string) []Token {
+	var tokens []Token
+	var cjk []int // offsets of the current run of CJK characters
+	word := -1    // start of the current non CJK word
+	flushCJK := func(end int) {
+		if len(cjk) == 1 {
+			tokens = append(tokens, newToken(text, cjk[0], end))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			bigramEnd := end
+			if i+2 < len(cjk) {
+				bigramEnd = cjk[i+2]
+			}
+			tokens = append(tokens, newToken(text, cjk[i], bigramEnd))
+		}
+		cjk = cjk[:0]
+	}
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case isCJK(r):
+			if word >= 0 {
+				tokens = append(tokens, newToken(text, word, i))
+				word = -1
+			}
+			cjk = append(cjk, i)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK(i)
+			if word < 0 {
+				word = i
+			}
+		default:
+			flushCJK(i)
+			if word >= 0 {
+				tokens = append(tokens, newToken(text, word, i))
+				word = -1
+			}
+		}
+		i += size
+	}
+	flushCJK(len(text))
+	if word >= 0 {
+		tokens = append(tokens, newToken(text, word, len(text)))
+	}
+	return tokens
+}
+
+// newTokenizer create a tokenizer by the tokenizer tag,
+// whitespace/ngram/ngram:N/bigram is supported
+func newTokenizer(tag string) (Tokenizer, error) {
+	name, param, _ := strings.Cut(strings.TrimSpace(tag), ":")
+	switch name {
+	case "", "whitespace":
+		return WhitespaceTokenizer{}, nil
+	case "ngram":
+		if param == "" {
+			return NGramTokenizer{N: 2}, nil
+		}
+		n, err := cast.ToIntE(param)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid ngram size(%s)", param)
+		}
+		return NGramTokenizer{N: n}, nil
+	case "bigram":
+		return BigramTokenizer{}, nil
+	}
+	return nil, fmt.Errorf("not support tokenizer(%s)", tag)
+}
+
+// uniqueTerms remove the duplicate terms and keep the order
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	out := terms[:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			out = append(out, term)
+		}
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+// Update by query
+// The fields tagged update:"true" can be assigned to the datas which meet the searchers,
+// e.g. set status=archived where updated_at < X, the fields are written by their offsets
+
+package search
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/cast"
+)
+
+// Assignment set the field to the value, the value is cast to the field's type like Searcher.Value
+type Assignment struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+// assignment a checked Assignment
+type assignment struct {
+	info  *fieldInfo
+	value interface{} // cast to the field's kind
+}
+
+// newUpdateInfo return the info of the field if it is tagged update:"true", nil otherwise
+func newUpdateInfo(field reflect.StructField) (*fieldInfo, error) {
+	tag := field.Tag
+	if tag.Get("update") != "true" {
+		return nil, nil
+	}
+	if !field.IsExported() {
+		// it could be written by offset, but not by reflect under the search_safe tag
+		return nil, fmt.Errorf("field(%s) tagged update must be exported", field.Name)
+	}
+	jsonTag := tag.Get("json")
+	if jsonTag == "" {
+		return nil, fmt.Errorf("field(%s) tagged update must have a json tag", field.Name)
+	}
+	kind := field.Type.Kind()
+	if kind != reflect.Bool && kind != reflect.String && (kind < reflect.Int || kind > reflect.Uint64) &&
+		kind != reflect.Float32 && kind != reflect.Float64 {
+		return nil, fmt.Errorf("field(%s) of kind %s does not support update", jsonTag, kind)
+	}
+	return &fieldInfo{index: field.Index[0], offset: field.Offset, kind: kind}, nil
+}
+
+// checkAssignments check the assignments by the update tags and cast their values
+func (s *SearcherLimit) checkAssignments(assignments []*Assignment) ([]assignment, error) {
+	if len(assignments) == 0 {
+		return nil, errors.New("assignments is empty")
+	}
+	checked := make([]assignment, len(assignments))
+	assigned := make(map[string]bool, len(assignments))
+	for k, a := range assignments {
+		if a == nil {
+			return nil, fmt.Errorf("assignments[%d] is nil", k)
+		}
+		info, ok := s.updateInfoMap[a.Field]
+		if !ok {
+			return nil, fmt.Errorf("field(%s) does not support update", a.Field)
+		}
+		if assigned[a.Field] {
+			return nil, fmt.Errorf("field(%s) is assigned more than once", a.Field)
+		}
+		assigned[a.Field] = true
+		var value interface{}
+		var err error
+		if info.kind == reflect.Bool {
+			value, err = cast.ToBoolE(a.Value)
+		} else {
+			value, err = castValueE(a.Value, info.kind)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("assignments[%d] is invalid, %s", k, err.Error())
+		}
+		checked[k] = assignment{info: info, value: value}
+	}
+	return checked, nil
+}
+
+// Update set the assignments to the datas which meet all the searchers, datas are pointers of the limit's struct,
+// the searchers are checked like Compile. The assignments and datas are checked before any data is modified,
+// changed is the number of datas with at least one field changed
+func (s *SearcherLimit) Update(
+	datas []interface{}, searchers []*Searcher, assignments []*Assignment,
+) (changed int, err error) {
+	query, err := s.Compile(searchers)
+	if err != nil {
+		return 0, err
+	}
+	return query.Update(datas, assignments)
+}
+
+// Update set the assignments to the datas which meet all the searchers like SearcherLimit.Update
+func (q *Query) Update(datas []interface{}, assignments []*Assignment) (changed int, err error) {
+	checked, err := q.limit.checkAssignments(assignments)
+	if err != nil {
+		return 0, err
+	}
+	for i, data := range datas {
+		if err = q.limit.checkData(data, i); err != nil {
+			return 0, err
+		}
+	}
+	for _, data := range datas {
+		if !matchAll(q.searchers, data) {
+			continue
+		}
+		dataChanged := false
+		for _, a := range checked {
+			if setValue(fieldAt(data, a.info), a.info.kind, a.value) {
+				dataChanged = true
+			}
+		}
+		if dataChanged {
+			changed++
+		}
+	}
+	return changed, nil
+}
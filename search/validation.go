@@ -0,0 +1,120 @@
+// Validity check of all searchers
+// ValidCheck stops at the first invalid searcher, ValidCheckAll checks every searcher
+// and returns the reasons together, so a form can mark all the invalid conditions at once.
+// The errors can be marshaled to json or returned by a grpc handler with BadRequest and ErrorInfo details
+
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+)
+
+// ValidationError the reason why a searcher is invalid
+type ValidationError struct {
+	Index    int      `json:"index"`             // index of the searcher
+	Field    string   `json:"field"`             // field of the searcher
+	Operator string   `json:"operator"`          // name of the searcher's operator
+	Reason   string   `json:"reason"`            // why the searcher is invalid
+	Allowed  []string `json:"allowed,omitempty"` // names of the operators supported by the field
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("searchers[%d] is invalid, %s", e.Index, e.Reason)
+}
+
+// ValidationErrors the errors of all invalid searchers in order
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for k, ve := range e {
+		msgs[k] = ve.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// GRPCStatus the status returned by a grpc handler, codes.InvalidArgument with a BadRequest detail,
+// each field violation is an invalid searcher, and an ErrorInfo detail for each searcher
+// whose operator is not supported, its metadata has the operator and the allowed operators
+func (e ValidationErrors) GRPCStatus() *status.Status {
+	st := status.New(codes.InvalidArgument, e.Error())
+	badRequest := &errdetails.BadRequest{}
+	details := []protoiface.MessageV1{badRequest}
+	for _, ve := range e {
+		field := fmt.Sprintf("searchers[%d]", ve.Index)
+		if ve.Field != "" {
+			field += "." + ve.Field
+		}
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field: field, Description: ve.Reason,
+		})
+		if len(ve.Allowed) > 0 && !containsString(ve.Allowed, ve.Operator) {
+			details = append(details, &errdetails.ErrorInfo{
+				Reason: "SEARCH_OPERATOR_NOT_SUPPORTED",
+				Domain: "search",
+				Metadata: map[string]string{
+					"searcher": field, "operator": ve.Operator, "allowed": strings.Join(ve.Allowed, "/"),
+				},
+			})
+		}
+	}
+	if detailed, err := st.WithDetails(details...); err == nil {
+		return detailed
+	}
+	return st
+}
+
+// containsString whether strs contains str
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+// operatorName the name of the search operator
+func operatorName(so SearchOperator) string {
+	if int(so) < 0 || int(so) >= len(searchOperatorName) {
+		return fmt.Sprintf("unknow(%d)", so)
+	}
+	return searchOperatorName[so]
+}
+
+// ValidCheckAll check every searcher like Compile, the searchers are not modified.
+// It returns the compiled Query if all the searchers are valid,
+// otherwise a nil Query and ValidationErrors of all the invalid searchers
+func (s *SearcherLimit) ValidCheckAll(searchers []*Searcher) (*Query, error) {
+	var errs ValidationErrors
+	compiled := make([]*Searcher, len(searchers))
+	for k, searcher := range searchers {
+		if searcher == nil {
+			errs = append(errs, &ValidationError{Index: k, Reason: "searcher is nil"})
+			continue
+		}
+		ve := &ValidationError{Index: k, Field: searcher.Field, Operator: operatorName(searcher.SearchOperator)}
+		if searchLimit, ok := s.limit[searcher.Field]; ok {
+			for _, so := range searchLimit.SearchOperators {
+				ve.Allowed = append(ve.Allowed, operatorName(so))
+			}
+		}
+		c := &Searcher{Field: searcher.Field, SearchOperator: searcher.SearchOperator, Value: searcher.Value}
+		if reason := s.checkSearcher(c); reason != nil {
+			ve.Reason = reason.Error()
+			errs = append(errs, ve)
+			continue
+		}
+		compiled[k] = c
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &Query{limit: s, searchers: compiled}, nil
+}
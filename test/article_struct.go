@@ -0,0 +1,9 @@
+package test
+
+//go:generate go run go_tests/cmd/searchgen -type Article
+
+type Article struct {
+	ID    int    `json:"id" db:"article_id" search:"lt,lte,eq,gte,gt,neq,in"`
+	Title string `json:"title" gorm:"column:article_title" search:"contain,eq,match,prefix,fuzzy" tokenizer:"bigram" boost:"2"`
+	Body  string `json:"body" bson:"content" search:"contain,notcontain,match,prefix,fuzzy,regex"`
+}
@@ -0,0 +1,198 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"go_tests/binder"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+type BindPage struct {
+	Page int    `json:"page"`
+	Size uint8  `json:"size"`
+	Sort string `json:"sort"`
+}
+
+type BindFilter struct {
+	MinScore float32 `json:"min_score"`
+	Owner    *BindOwner
+}
+
+type BindOwner struct {
+	Name string `json:"name"`
+}
+
+type BindRequest struct {
+	BindPage
+	Keyword string      `json:"keyword"`
+	IDs     []int64     `json:"ids"`
+	Debug   bool        `json:"debug"`
+	Since   time.Time   `json:"since"`
+	Filter  BindFilter  `json:"filter"`
+	Extra   interface{} `json:"extra"`
+	Ignored string      `json:"-"`
+}
+
+type BindBase struct {
+	Name  string `json:"name"`
+	Kind  string
+	Title string
+}
+
+type BindOther struct {
+	Kind    string
+	Heading string `json:"Title"`
+}
+
+type BindNamed struct {
+	BindBase
+	Name string `json:"name"`
+	BindOther
+}
+
+func TestBindDominant(t *testing.T) {
+	req := &BindNamed{}
+	if err := binder.BindStrings(req, map[string]string{"name": "outer", "Kind": "k", "Title": "t"}); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("%+v\n", *req)
+	// the shallower field wins even if it is declared later, the same depth conflict is dropped
+	// unless only one of them is tagged
+	if req.Name != "outer" || req.BindBase.Name != "" || req.BindBase.Kind != "" || req.BindOther.Kind != "" ||
+		req.Heading != "t" || req.Title != "" {
+		t.Fatalf("unexpected bound request %+v", *req)
+	}
+}
+
+func TestBindValues(t *testing.T) {
+	values, err := url.ParseQuery("page=2&size=20&keyword=go&ids=1&ids=2&debug=true" +
+		"&since=2024-01-02T03:04:05Z&filter.min_score=0.5&filter.Owner.name=tom&unknown=1&Ignored=x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &BindRequest{}
+	if err = binder.BindValues(req, values); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("%+v %+v\n", *req, *req.Filter.Owner)
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if req.Page != 2 || req.Size != 20 || req.Keyword != "go" || !reflect.DeepEqual(req.IDs, []int64{1, 2}) ||
+		!req.Debug || !req.Since.Equal(since) || req.Filter.MinScore != 0.5 || req.Filter.Owner.Name != "tom" ||
+		req.Ignored != "" {
+		t.Fatalf("unexpected bound request %+v", *req)
+	}
+
+	// a single value of a slice field is split by comma
+	req = &BindRequest{}
+	if err = binder.BindStrings(req, map[string]string{"ids": "3, 4,5", "sort": "name"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(req.IDs, []int64{3, 4, 5}) || req.Sort != "name" {
+		t.Fatalf("unexpected bound request %+v", *req)
+	}
+}
+
+func TestBindMap(t *testing.T) {
+	req := &BindRequest{}
+	err := binder.BindMap(req, map[string]interface{}{
+		"page":    float64(3),
+		"ids":     []interface{}{float64(7), "8"},
+		"since":   "2024-01-02",
+		"filter":  map[string]interface{}{"min_score": "1.5", "Owner": map[string]interface{}{"name": "amy"}},
+		"extra":   map[string]interface{}{"k": "v"},
+		"keyword": 12,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("%+v\n", *req)
+	if req.Page != 3 || !reflect.DeepEqual(req.IDs, []int64{7, 8}) || req.Since.Day() != 2 ||
+		req.Filter.MinScore != 1.5 || req.Filter.Owner == nil || req.Filter.Owner.Name != "amy" ||
+		req.Keyword != "12" || !reflect.DeepEqual(req.Extra, map[string]interface{}{"k": "v"}) {
+		t.Fatalf("unexpected bound request %+v", *req)
+	}
+}
+
+func TestBindMetadata(t *testing.T) {
+	md := metadata.Pairs("Page", "5", "keyword", "grpc", "ids", "9", "ids", "10")
+	req := &BindRequest{}
+	if err := binder.BindMetadata(req, md); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("%+v\n", *req)
+	if req.Page != 5 || req.Keyword != "grpc" || !reflect.DeepEqual(req.IDs, []int64{9, 10}) {
+		t.Fatalf("unexpected bound request %+v", *req)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	req := &BindRequest{}
+	err := binder.BindStrings(req, map[string]string{
+		"page": "x", "size": "300", "ids": "1,y", "since": "yesterday", "keyword": "ok",
+	})
+	fmt.Println(err)
+	var errs binder.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expect binder.Errors, got %v", err)
+	}
+	var fields []string
+	for _, fe := range errs {
+		fields = append(fields, fe.Field)
+	}
+	if !reflect.DeepEqual(fields, []string{"ids", "page", "since", "size"}) {
+		t.Fatalf("unexpected failed fields %v", fields)
+	}
+	// the valid fields are still bound
+	if req.Keyword != "ok" {
+		t.Fatalf("keyword is not bound: %+v", *req)
+	}
+
+	if err = binder.BindMap(BindRequest{}, nil); err == nil {
+		t.Fatal("expect an error for a struct value")
+	}
+	var nilReq *BindRequest
+	if err = binder.BindMap(nilReq, nil); err == nil {
+		t.Fatal("expect an error for a nil pointer")
+	}
+}
+
+func TestBindDecimal(t *testing.T) {
+	req := &BindRequest{}
+	// form values are decimal, leading zeros are not octal
+	if err := binder.BindStrings(req, map[string]string{"page": "010", "size": "08", "ids": "07,010"}); err != nil {
+		t.Fatal(err)
+	}
+	if req.Page != 10 || req.Size != 8 || !reflect.DeepEqual(req.IDs, []int64{7, 10}) {
+		t.Fatalf("unexpected bound request %+v", *req)
+	}
+	if err := binder.BindStrings(req, map[string]string{"page": "0x10"}); err == nil {
+		t.Fatal("expect an error for a hex value")
+	}
+
+	// floats are not truncated
+	err := binder.BindMap(req, map[string]interface{}{"page": 3.9, "size": float64(4), "ids": []interface{}{1.5}})
+	fmt.Println(err)
+	var errs binder.Errors
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Field != "ids" || errs[1].Field != "page" {
+		t.Fatalf("unexpected errors %v", err)
+	}
+	if req.Page != 10 || req.Size != 4 {
+		t.Fatalf("unexpected bound request %+v", *req)
+	}
+}
+
+func BenchmarkBindValues(b *testing.B) {
+	values := url.Values{"page": {"2"}, "size": {"20"}, "keyword": {"go"}, "ids": {"1", "2"}, "debug": {"true"}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		req := &BindRequest{}
+		if err := binder.BindValues(req, values); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
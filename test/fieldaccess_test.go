@@ -0,0 +1,139 @@
+package test
+
+import (
+	"fmt"
+	"go_tests/fieldaccess"
+	"sync"
+	"testing"
+)
+
+type accessInner struct {
+	Count uint16
+	Ratio float32
+}
+
+type AccessEmbedded struct {
+	Tag string
+}
+
+type AccessStruct struct {
+	*AccessEmbedded
+	ID     int8
+	Name   string
+	On     bool
+	Inner  accessInner
+	Next   *accessInner
+	Tags   []string
+	hidden int64
+}
+
+func mustField(t *testing.T, d *fieldaccess.Descriptor, path string) *fieldaccess.Field {
+	f, err := d.Field(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func expectPanic(t *testing.T, name string, fn func()) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("%s should panic", name)
+		}
+	}()
+	fn()
+}
+
+func TestFieldAccess(t *testing.T) {
+	d, err := fieldaccess.For[AccessStruct]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d2, _ := fieldaccess.Of(&AccessStruct{}); d2 != d {
+		t.Fatal("the descriptor is not cached")
+	}
+	data := &AccessStruct{}
+
+	mustField(t, d, "ID").SetInt(data, 300) // truncated like reflect
+	mustField(t, d, "Name").SetString(data, "wzyao")
+	mustField(t, d, "On").SetBool(data, true)
+	mustField(t, d, "Inner.Count").SetUint(data, 7)
+	mustField(t, d, "Inner.Ratio").SetFloat(data, 0.5)
+	mustField(t, d, "hidden").SetInt(data, -1)
+	if data.ID != 44 || data.Name != "wzyao" || !data.On || data.Inner.Count != 7 || data.Inner.Ratio != 0.5 ||
+		data.hidden != -1 {
+		t.Fatalf("data = %+v", *data)
+	}
+
+	// nil pointers on the path read as zero and are allocated by setters
+	next, tag := mustField(t, d, "Next.Count"), mustField(t, d, "Tag")
+	if next.GetUint(data) != 0 || tag.GetString(data) != "" || next.Get(data) != uint16(0) {
+		t.Fatal("nil pointer on the path should read zero")
+	}
+	next.SetUint(data, 9)
+	tag.SetString(data, "t")
+	if data.Next == nil || data.Next.Count != 9 || data.AccessEmbedded == nil || data.Tag != "t" {
+		t.Fatalf("data = %+v", *data)
+	}
+	if next.GetUint(data) != 9 || tag.GetString(data) != "t" || mustField(t, d, "ID").GetInt(data) != 44 {
+		t.Fatal("get after set")
+	}
+
+	tags := mustField(t, d, "Tags")
+	if err = tags.Set(data, []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(tags.Get(data)) != "[a b]" {
+		t.Fatalf("Tags = %v", tags.Get(data))
+	}
+	if err = tags.Set(data, "a"); err == nil {
+		t.Fatal("string is not assignable to []string")
+	}
+	if err = tags.Set(data, nil); err != nil || data.Tags != nil {
+		t.Fatalf("Set(nil) = %v, %v", err, data.Tags)
+	}
+
+	for _, path := range []string{"Missing", "Name.Len", "Inner.Missing", ""} {
+		_, err = d.Field(path)
+		fmt.Println(path, err)
+		if err == nil {
+			t.Errorf("field(%s) should not be found", path)
+		}
+	}
+	if _, err = fieldaccess.Of(SimpleStruct{}); err == nil {
+		t.Fatal("a struct value has no descriptor")
+	}
+
+	expectPanic(t, "GetString of int8", func() { mustField(t, d, "ID").GetString(data) })
+	expectPanic(t, "wrong type", func() { mustField(t, d, "ID").GetInt(&SimpleStruct{}) })
+	expectPanic(t, "nil pointer", func() { mustField(t, d, "ID").GetInt((*AccessStruct)(nil)) })
+}
+
+func TestFieldAccessConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			data := &SimpleStruct1{}
+			for i := 0; i < 100; i++ {
+				d, err := fieldaccess.For[SimpleStruct1]()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				f, err := d.Field("B.A")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				f.SetInt(data, int64(g))
+				if data.B.A != g {
+					t.Errorf("B.A = %d, want %d", data.B.A, g)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
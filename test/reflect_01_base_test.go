@@ -7,7 +7,8 @@ import (
 	"testing"
 )
 
-func populateStructReflect(in interface{}) error {
+// populateStructReflect 每次按名称查找成员并赋值，作为fieldaccess的对比基准
+func populateStructReflect(in interface{}, name string, value int64) error {
 	val := reflect.ValueOf(in)
 	if val.Type().Kind() != reflect.Ptr {
 		return fmt.Errorf("you must pass in a pointer")
@@ -16,8 +17,11 @@ func populateStructReflect(in interface{}) error {
 	if elmv.Type().Kind() != reflect.Struct {
 		return fmt.Errorf("you must pass in a pointer to a struct")
 	}
-	fval := elmv.FieldByName("B")
-	fval.SetInt(42)
+	fval := elmv.FieldByName(name)
+	if !fval.IsValid() {
+		return fmt.Errorf("struct does not have field %s", name)
+	}
+	fval.SetInt(value)
 	return nil
 }
 
@@ -25,7 +29,7 @@ func BenchmarkReflectBase(b *testing.B) { // 反射赋值基础测试
 	b.ReportAllocs()
 	var m SimpleStruct
 	for i := 0; i < b.N; i++ {
-		if err := populateStructReflect(&m); err != nil {
+		if err := populateStructReflect(&m, "B", 42); err != nil {
 			b.Fatal(err)
 		}
 		if m.B != 42 {
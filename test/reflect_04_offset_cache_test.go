@@ -2,6 +2,7 @@ package test
 
 import (
 	"fmt"
+	"go_tests/fieldaccess"
 	"reflect"
 	"testing"
 	"unsafe"
@@ -49,3 +50,24 @@ func BenchmarkReflectWithUnsafe1(b *testing.B) {
 		}
 	}
 }
+
+// 嵌套路径经过指针成员时，每一段偏移量之间需要解引用一次
+
+func BenchmarkReflectWithUnsafeNested(b *testing.B) {
+	b.ReportAllocs()
+	m := SimpleStruct1{B: &SimpleStruct{}}
+	d, err := fieldaccess.For[SimpleStruct1]()
+	if err != nil {
+		b.Fatal(err)
+	}
+	f, err := d.Field("B.B")
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		f.SetInt(&m, 42)
+		if m.B.B != 42 {
+			b.Fatalf("unexpected value %d for B.B", m.B.B)
+		}
+	}
+}
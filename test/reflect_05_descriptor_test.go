@@ -1,46 +1,46 @@
 package test
 
 import (
-	"fmt"
-	"reflect"
+	"go_tests/fieldaccess"
 	"testing"
-	"unsafe"
 )
 
-type typeDescriptor uintptr
+// 预先获取类型描述和成员，类型化的SetInt直接写入
 
-func describeType(in interface{}) (typeDescriptor, error) {
-	typ := reflect.TypeOf(in)
-	if typ.Kind() != reflect.Ptr {
-		return 0, fmt.Errorf("you must pass in a pointer")
-	}
-	if typ.Elem().Kind() != reflect.Struct {
-		return 0, fmt.Errorf("you must pass in a pointer to a struct")
+func BenchmarkPopulateUnsafe3(b *testing.B) {
+	b.ReportAllocs()
+	var m SimpleStruct
+	d, err := fieldaccess.For[SimpleStruct]()
+	if err != nil {
+		b.Fatal(err)
 	}
-	f, ok := typ.Elem().FieldByName("B")
-	if !ok {
-		return 0, fmt.Errorf("struct does not have field B")
+	f, err := d.Field("B")
+	if err != nil {
+		b.Fatal(err)
 	}
-	if f.Type.Kind() != reflect.Int {
-		return 0, fmt.Errorf("field B should be an int")
+	for i := 0; i < b.N; i++ {
+		f.SetInt(&m, 42)
+		if m.B != 42 {
+			b.Fatalf("unexpected value %d for B", m.B)
+		}
 	}
-	return typeDescriptor(f.Offset), nil
 }
 
-func populateStructUnsafe3(in interface{}, ti typeDescriptor) error {
-	structPtr := (*intface)(unsafe.Pointer(&in)).value
-	*(*int)(unsafe.Pointer(uintptr(structPtr) + uintptr(ti))) = 42
-	return nil
-}
-func BenchmarkPopulateUnsafe3(b *testing.B) {
+// 通用的Set需要经过reflect.NewAt，作为SetInt的对比
+
+func BenchmarkPopulateSet(b *testing.B) {
 	b.ReportAllocs()
 	var m SimpleStruct
-	descriptor, err := describeType((*SimpleStruct)(nil))
+	d, err := fieldaccess.For[SimpleStruct]()
+	if err != nil {
+		b.Fatal(err)
+	}
+	f, err := d.Field("B")
 	if err != nil {
 		b.Fatal(err)
 	}
 	for i := 0; i < b.N; i++ {
-		if err := populateStructUnsafe3(&m, descriptor); err != nil {
+		if err = f.Set(&m, 42); err != nil {
 			b.Fatal(err)
 		}
 		if m.B != 42 {
@@ -2,6 +2,7 @@ package test
 
 import (
 	"fmt"
+	"go_tests/search"
 	"reflect"
 	"testing"
 	"unsafe"
@@ -44,4 +45,10 @@ func TestInterfaceNil(t *testing.T) {
 	var simpleStructPtr *SimpleStruct
 	ints = append(ints, nil, simpleStructPtr)
 	interfaceNil(ints)
+	// search.IsNil covers both cases
+	for k, v := range ints {
+		if !search.IsNil(v) {
+			t.Fatalf("ints[%d] should be nil", k)
+		}
+	}
 }
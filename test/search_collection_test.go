@@ -0,0 +1,218 @@
+package test
+
+import (
+	"fmt"
+	"go_tests/search"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// filterBy filter datas by Searcher.Filter one by one
+func filterBy(limit *search.SearcherLimit, datas []interface{}, searchers []*search.Searcher) ([]interface{}, error) {
+	if err := limit.ValidCheck(searchers); err != nil {
+		return nil, err
+	}
+	var err error
+	for _, s := range searchers {
+		if datas, err = s.Filter(limit, datas); err != nil {
+			return nil, err
+		}
+	}
+	return datas, nil
+}
+
+func TestSearchCollection(t *testing.T) {
+	c := search.NewCollection(searchLimit)
+	if err := c.AddHashIndex("str"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddOrderedIndex("b"); err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(1))
+	var datas []*SimpleStruct
+	for i := 0; i < 500; i++ {
+		data := &SimpleStruct{A: r.Intn(10), B: r.Intn(100), Str: fmt.Sprintf("wzyao%d", r.Intn(20))}
+		if _, err := c.Insert(data); err != nil {
+			t.Fatal(err)
+		}
+		datas = append(datas, data)
+	}
+	// the index of a is added after datas are inserted
+	if err := c.AddHashIndex("a"); err != nil {
+		t.Fatal(err)
+	}
+	// update some datas in place and delete some datas
+	for id := 0; id < 500; id += 7 {
+		datas[id].B = r.Intn(100)
+		datas[id].Str = fmt.Sprintf("wzyao%d", r.Intn(20))
+		if err := c.Update(uint64(id), datas[id]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var all []interface{}
+	for id, data := range datas {
+		if id%11 == 0 {
+			if err := c.Delete(uint64(id)); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		all = append(all, data)
+	}
+	if c.Len() != len(all) {
+		t.Fatalf("collection len %d, want %d", c.Len(), len(all))
+	}
+
+	cases := []struct {
+		searchers []*search.Searcher
+		plan      string
+	}{
+		{[]*search.Searcher{{Field: "str", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "wzyao3"}}, "hash index(str) equal"},
+		{[]*search.Searcher{{Field: "str", SearchOperator: search.SEARCH_OPERATOR_IN, Value: "wzyao3,wzyao5,wzyao3"}}, "hash index(str) in"},
+		{[]*search.Searcher{{Field: "b", SearchOperator: search.SEARCH_OPERATOR_GREATER, Value: "90"}}, "ordered index(b) greater"},
+		{[]*search.Searcher{{Field: "b", SearchOperator: search.SEARCH_OPERATOR_LESS_EQUAL, Value: "10"}}, "ordered index(b) less than or equal"},
+		{[]*search.Searcher{{Field: "b", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "50"}}, "ordered index(b) equal"},
+		{[]*search.Searcher{
+			{Field: "b", SearchOperator: search.SEARCH_OPERATOR_GREATER_EQUAL, Value: "30"},
+			{Field: "a", SearchOperator: search.SEARCH_OPERATOR_IN, Value: "1,2"},
+		}, "hash index(a) in"},
+		{[]*search.Searcher{
+			{Field: "b", SearchOperator: search.SEARCH_OPERATOR_LESS, Value: "60"},
+			{Field: "str", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "1"},
+		}, "ordered index(b) less than"},
+		{[]*search.Searcher{{Field: "str", SearchOperator: search.SEARCH_OPERATOR_NOT_CONTAIN, Value: "1"}}, "scan"},
+	}
+	for k, cs := range cases {
+		plan, err := c.Explain(cs.searchers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plan != cs.plan {
+			t.Errorf("cases[%d] plan is %s, want %s", k, plan, cs.plan)
+		}
+		got, err := c.Query(cs.searchers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := filterBy(searchLimit, all, cs.searchers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("cases[%d] got %d datas, want %d datas", k, len(got), len(want))
+		}
+		fmt.Printf("cases[%d] %s: %d datas\n", k, plan, len(got))
+	}
+}
+
+func TestSearchCollectionNaN(t *testing.T) {
+	wideLimit, err := search.NewSearcherLimit(&WideStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashed, ordered := search.NewCollection(wideLimit), search.NewCollection(wideLimit)
+	if err = hashed.AddHashIndex("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err = ordered.AddOrderedIndex("b"); err != nil {
+		t.Fatal(err)
+	}
+	datas := []*WideStruct{{A: 1, B: 1}, {A: 2, B: math.NaN()}, {A: 3, B: 3}, {A: 4, B: math.NaN()}, {A: 5, B: 5}}
+	for _, c := range []*search.Collection{hashed, ordered} {
+		for _, data := range datas {
+			if _, err = c.Insert(data); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// the NaN rows are updated and deleted by their keys
+	datas[1].B = 2
+	for _, c := range []*search.Collection{hashed, ordered} {
+		if err = c.Update(1, datas[1]); err != nil {
+			t.Fatal(err)
+		}
+		if err = c.Delete(3); err != nil {
+			t.Fatal(err)
+		}
+	}
+	all := []interface{}{datas[0], datas[1], datas[2], datas[4]}
+	datas[2].B = math.NaN()
+	for _, c := range []*search.Collection{hashed, ordered} {
+		if err = c.Update(2, datas[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cases := [][]*search.Searcher{
+		{{Field: "b", SearchOperator: search.SEARCH_OPERATOR_LESS_EQUAL, Value: "NaN"}},
+		{{Field: "b", SearchOperator: search.SEARCH_OPERATOR_GREATER_EQUAL, Value: "NaN"}},
+		{{Field: "b", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "NaN"}},
+		{{Field: "b", SearchOperator: search.SEARCH_OPERATOR_LESS, Value: "5"}},
+		{{Field: "b", SearchOperator: search.SEARCH_OPERATOR_GREATER, Value: "0"}},
+		{{Field: "b", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "2"}},
+	}
+	for k, searchers := range cases {
+		want, err := filterBy(wideLimit, all, searchers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range []*search.Collection{hashed, ordered} {
+			plan, _ := c.Explain(searchers)
+			got, err := c.Query(searchers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fmt.Printf("cases[%d] %s: %d datas\n", k, plan, len(got))
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("cases[%d] %s got %v, want %v", k, plan, got, want)
+			}
+		}
+	}
+}
+
+func TestSearchCollectionInvalidData(t *testing.T) {
+	c := search.NewCollection(searchLimit)
+	id, err := c.Insert(&SimpleStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		data interface{}
+		want string
+	}{
+		{nil, "param data is nil"},
+		{(*SimpleStruct)(nil), "param data is a nil pointer"},
+		{&WideStruct{}, "param data's type is invalid"},
+	}
+	for k, cs := range cases {
+		_, err := c.Insert(cs.data)
+		fmt.Println(err)
+		if err == nil || err.Error() != cs.want {
+			t.Errorf("cases[%d] Insert got %v, want %s", k, err, cs.want)
+		}
+		if err = c.Update(id, cs.data); err == nil || err.Error() != cs.want {
+			t.Errorf("cases[%d] Update got %v, want %s", k, err, cs.want)
+		}
+	}
+}
+
+func BenchmarkSearchCollectionQuery(b *testing.B) {
+	b.ReportAllocs()
+	c := search.NewCollection(searchLimit)
+	if err := c.AddHashIndex("str"); err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < 100000; i++ {
+		if _, err := c.Insert(&SimpleStruct{A: i, B: i % 100, Str: fmt.Sprintf("wzyao%d", i)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		searchers := []*search.Searcher{{Field: "str", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "wzyao500"}}
+		if datas, err := c.Query(searchers); err != nil || len(datas) != 1 {
+			b.Fatalf("query %v %v", datas, err)
+		}
+	}
+}
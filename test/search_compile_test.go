@@ -0,0 +1,95 @@
+package test
+
+import (
+	"fmt"
+	"go_tests/search"
+	"sync"
+	"testing"
+)
+
+// WideStruct has the same json names as SimpleStruct with different types
+type WideStruct struct {
+	A   int64   `json:"a" search:"lt,lte,eq,gte,gt,neq,in"`
+	B   float64 `json:"b" search:"lt,lte,eq,gte,gt,neq"`
+	Str string  `json:"str" search:"contain,eq,in,regex"`
+}
+
+func TestSearchCompileConcurrent(t *testing.T) {
+	wideLimit, err := search.NewSearcherLimit(&WideStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the definition is shared by all goroutines and both limits
+	searchers := []*search.Searcher{
+		{Field: "a", SearchOperator: search.SEARCH_OPERATOR_IN, Value: "1,3,5"},
+		{Field: "b", SearchOperator: search.SEARCH_OPERATOR_LESS, Value: "40"},
+		{Field: "str", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "wzyao"},
+	}
+	simpleDatas := []interface{}{
+		&SimpleStruct{A: 1, B: 10, Str: "wzyao"},
+		&SimpleStruct{A: 2, B: 20, Str: "wzyao"},
+		&SimpleStruct{A: 3, B: 30, Str: "x"},
+		&SimpleStruct{A: 5, B: 30, Str: "wzyao"},
+	}
+	wideDatas := []interface{}{
+		&WideStruct{A: 1, B: 39.5, Str: "wzyao"},
+		&WideStruct{A: 5, B: 40, Str: "wzyao"},
+	}
+	shared, err := searchLimit.Compile(searchers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			limit, datas, want := searchLimit, simpleDatas, 2
+			if g%2 == 1 {
+				limit, datas, want = wideLimit, wideDatas, 1
+			}
+			for i := 0; i < 100; i++ {
+				query, err := limit.Compile(searchers)
+				if err != nil {
+					errs <- err
+					return
+				}
+				datasOut, err := query.Filter(datas)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if len(datasOut) != want {
+					errs <- fmt.Errorf("goroutine %d got %d datas, want %d", g, len(datasOut), want)
+					return
+				}
+				if datasOut, _ = shared.Filter(simpleDatas); len(datasOut) != 2 {
+					errs <- fmt.Errorf("shared query got %d datas", len(datasOut))
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	if ok, err := shared.Match(simpleDatas[3]); err != nil || !ok {
+		t.Fatalf("Match = %v, %v", ok, err)
+	}
+	if _, err = shared.Match(wideDatas[0]); err == nil {
+		t.Fatal("the query of SimpleStruct should not match WideStruct")
+	}
+	if _, err = wideLimit.Compile([]*search.Searcher{
+		{Field: "str", SearchOperator: search.SEARCH_OPERATOR_REGEX, Value: "("},
+	}); err == nil {
+		t.Fatal("invalid regex should fail")
+	}
+	if _, err = searchLimit.Compile([]*search.Searcher{nil}); err == nil {
+		t.Fatal("nil searcher should fail")
+	}
+}
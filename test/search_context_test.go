@@ -0,0 +1,73 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go_tests/search"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// cancelAfterContext a context which is done after Err is called n times
+type cancelAfterContext struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfterContext) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestSearchFilterContext(t *testing.T) {
+	searcher := &search.Searcher{Field: "b", SearchOperator: search.SEARCH_OPERATOR_LESS, Value: "50"}
+	if err := searchLimit.ValidCheckContext(context.Background(), []*search.Searcher{searcher}); err != nil {
+		t.Fatal(err)
+	}
+	datas := parallelDatas(5000)
+	datasOut, err := searcher.FilterContext(context.Background(), searchLimit, datas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(datasOut) != 2500 {
+		t.Fatalf("got %d datas", len(datasOut))
+	}
+
+	// ctx is done after 2 checks, so 2048 datas are filtered
+	datasOut, err = searcher.FilterContext(&cancelAfterContext{Context: context.Background(), n: 2}, searchLimit, datas)
+	fmt.Println(len(datasOut), err)
+	var progress *search.ProgressError
+	if !errors.As(err, &progress) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v", err)
+	}
+	if progress.Processed != 2048 || progress.Total != 5000 || len(datasOut) != 1048 {
+		t.Fatalf("progress = %+v, got %d datas", *progress, len(datasOut))
+	}
+	if status.Code(err) != codes.Canceled {
+		t.Fatalf("grpc code = %s", status.Code(err))
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, err = searcher.FilterContext(ctx, searchLimit, datas)
+	fmt.Println(err)
+	if !errors.Is(err, context.DeadlineExceeded) || status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("err = %v", err)
+	}
+	if err = searchLimit.ValidCheckContext(ctx, []*search.Searcher{searcher}); err != context.DeadlineExceeded {
+		t.Fatalf("err = %v", err)
+	}
+	err = searchLimit.ValidCheckContext(context.Background(), []*search.Searcher{
+		searcher, {Field: "b", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "1"},
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "searchers[1] is invalid") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"go_tests/search"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type DiffAudit struct {
+	UpdatedBy string `json:"updated_by"`
+}
+
+type DiffAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type DiffStruct struct {
+	DiffAudit
+	ID        int          `json:"id"`
+	Name      string       `json:"name"`
+	Tags      []string     `json:"tags"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Address   DiffAddress  `json:"address"`
+	Billing   *DiffAddress `json:"billing,omitempty"`
+	Note      string       `json:"note,omitempty"`
+	Ratio     *float64     `json:"ratio"`
+	Secret    string       `json:"-"`
+	Untagged  string
+}
+
+func TestSearchDiff(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	ratio1, ratio2 := 0.5, 0.5
+	old := &DiffStruct{
+		DiffAudit: DiffAudit{UpdatedBy: "tom"}, ID: 1, Name: "a/b", Tags: []string{"x"}, UpdatedAt: now,
+		Address: DiffAddress{City: "paris"}, Billing: &DiffAddress{City: "rome"}, Note: "n", Ratio: &ratio1,
+	}
+	// the same instant in another location and an equal value behind another pointer are not changes
+	same := *old
+	same.UpdatedAt, same.Ratio = now.In(time.FixedZone("x", 3600)), &ratio2
+	changes, err := search.Diff(old, &same)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expect no changes, got %+v", changes[0])
+	}
+
+	newer := *old
+	newer.UpdatedBy = "amy"
+	newer.Tags = []string{"x", "y"}
+	newer.UpdatedAt = now.Add(time.Hour)
+	newer.Address = DiffAddress{City: "paris", Zip: "75001"}
+	newer.Billing = &DiffAddress{City: "milan"}
+	newer.Note = ""
+	newer.Secret, newer.Untagged = "s", "u"
+	changes, err = search.Diff(*old, newer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields, ops []string
+	for _, c := range changes {
+		fmt.Printf("%+v\n", *c)
+		fields, ops = append(fields, c.Field), append(ops, c.Op)
+	}
+	if !reflect.DeepEqual(fields, []string{"updated_by", "tags", "updated_at", "address.zip", "billing.city", "note"}) {
+		t.Fatalf("unexpected changed fields %v", fields)
+	}
+	if !reflect.DeepEqual(ops, []string{"replace", "replace", "replace", "add", "replace", "remove"}) {
+		t.Fatalf("unexpected operations %v", ops)
+	}
+	if changes[1].Old.([]string)[0] != "x" || changes[4].New != "milan" {
+		t.Fatalf("unexpected values %+v %+v", *changes[1], *changes[4])
+	}
+
+	newer.Billing, newer.Ratio = nil, nil
+	changes, err = search.Diff(old, &newer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, err := changes.JSONPatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(string(patch))
+	var ops2 []map[string]interface{}
+	if err = json.Unmarshal(patch, &ops2); err != nil {
+		t.Fatal(err)
+	}
+	last := ops2[len(ops2)-1]
+	if last["op"] != "replace" || last["path"] != "/ratio" || last["value"] != nil {
+		t.Fatalf("unexpected last operation %v", last)
+	}
+	if billing := ops2[len(ops2)-3]; billing["op"] != "remove" || billing["path"] != "/billing" {
+		t.Fatalf("unexpected billing operation %v", billing)
+	}
+}
+
+type DiffOwner struct {
+	Owner string `json:"owner"`
+	Team  string `json:"team,omitempty"`
+}
+
+type DiffEmbedded struct {
+	*DiffOwner
+	ID int `json:"id"`
+}
+
+func TestSearchDiffEmbeddedNil(t *testing.T) {
+	// the promoted fields of a nil embedded pointer are not in the json, so they are added or removed
+	changes, err := search.Diff(&DiffEmbedded{ID: 1}, &DiffEmbedded{DiffOwner: &DiffOwner{}, ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, err := changes.JSONPatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(string(patch))
+	expect := `[{"op":"add","path":"/owner","value":""}]`
+	if string(patch) != expect {
+		t.Fatalf("expect %s, got %s", expect, patch)
+	}
+
+	changes, err = search.Diff(&DiffEmbedded{DiffOwner: &DiffOwner{Owner: "tom", Team: "a"}}, &DiffEmbedded{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patch, err = changes.JSONPatch(); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(string(patch))
+	expect = `[{"op":"remove","path":"/owner"},{"op":"remove","path":"/team"}]`
+	if string(patch) != expect {
+		t.Fatalf("expect %s, got %s", expect, patch)
+	}
+	if changes[0].Old != "tom" || changes[0].New != nil {
+		t.Fatalf("unexpected change %+v", *changes[0])
+	}
+}
+
+func TestSearchDiffInvalid(t *testing.T) {
+	cases := [][2]interface{}{
+		{nil, &DiffStruct{}},
+		{&DiffStruct{}, DiffStruct{}},
+		{&DiffStruct{}, (*DiffStruct)(nil)},
+		{1, 2},
+	}
+	for k, c := range cases {
+		if _, err := search.Diff(c[0], c[1]); err == nil {
+			t.Fatalf("case %d: expect an error", k)
+		} else {
+			fmt.Println(k, err)
+		}
+	}
+}
+
+func TestSearchDiffPatchEscape(t *testing.T) {
+	type escaped struct {
+		A string `json:"a/b~c"`
+	}
+	changes, err := search.Diff(escaped{A: "1"}, escaped{A: "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != "/a~1b~0c" {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+}
+
+func TestSearchDiffPatchZero(t *testing.T) {
+	changes, err := search.Diff(&DiffStruct{ID: 1, Name: "a"}, &DiffStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, err := changes.JSONPatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(string(patch))
+	expect := `[{"op":"replace","path":"/id","value":0},{"op":"replace","path":"/name","value":""}]`
+	if string(patch) != expect {
+		t.Fatalf("expect %s, got %s", expect, patch)
+	}
+}
+
+type diffHidden struct {
+	Hidden string `json:"hidden"`
+}
+
+type DiffUnexported struct {
+	diffHidden
+	ID int `json:"id"`
+}
+
+func TestSearchDiffUnexportedEmbedded(t *testing.T) {
+	// the fields promoted through an unexported embedded struct can not be read by reflect, so they are not compared
+	changes, err := search.Diff(&DiffUnexported{diffHidden{Hidden: "a"}, 1}, &DiffUnexported{diffHidden{Hidden: "b"}, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Field != "id" {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+}
+
+type DiffValues struct {
+	Score  float64        `json:"score"`
+	Values [1]interface{} `json:"values"`
+}
+
+func TestSearchDiffValues(t *testing.T) {
+	// NaN is not a change, and an array of interfaces holding slices is compared without panic
+	old := &DiffValues{Score: math.NaN(), Values: [1]interface{}{[]int{1}}}
+	changes, err := search.Diff(old, &DiffValues{Score: math.NaN(), Values: [1]interface{}{[]int{1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expect no changes, got %+v", *changes[0])
+	}
+	changes, err = search.Diff(old, &DiffValues{Score: 1, Values: [1]interface{}{[]int{2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Field != "score" || changes[1].Field != "values" {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSearchDistinct(t *testing.T) {
+	datasIn := []interface{}{
+		&SimpleStruct{A: 1, B: 10, Str: "shanghai"},
+		&SimpleStruct{A: 2, B: 10, Str: "beijing"},
+		&SimpleStruct{A: 1, B: 20, Str: "shanghai"},
+		&SimpleStruct{A: 1, B: 10, Str: "shenzhen"},
+		&SimpleStruct{A: 1, B: 10, Str: "shanghai"},
+	}
+	values, err := searchLimit.DistinctValues("str", datasIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(values)
+	if fmt.Sprint(values) != "[shanghai beijing shenzhen]" {
+		t.Fatalf("unexpected distinct values %v", values)
+	}
+
+	datasOut, err := searchLimit.DistinctRows([]string{"a", "b"}, datasIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(datasOut) != 3 || datasOut[0] != datasIn[0] || datasOut[1] != datasIn[1] || datasOut[2] != datasIn[2] {
+		t.Fatalf("unexpected distinct rows %v", datasOut)
+	}
+
+	datasOut, err = searchLimit.DistinctRows([]string{"a", "str"}, datasIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(datasOut) != 3 || datasOut[2] != datasIn[3] {
+		t.Fatalf("unexpected distinct rows %v", datasOut)
+	}
+
+	if _, err = searchLimit.DistinctValues("str_a", datasIn); err == nil {
+		t.Fatal("field str_a should not be allowed")
+	}
+	fmt.Println(err)
+}
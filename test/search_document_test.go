@@ -0,0 +1,130 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go_tests/search"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// documentCases the searchers translated by TestSearchToMongo and TestSearchToElasticsearch
+var documentCases = [][]*search.Searcher{
+	{},
+	{{Field: "id", SearchOperator: search.SEARCH_OPERATOR_GREATER_EQUAL, Value: "10"}},
+	{
+		{Field: "id", SearchOperator: search.SEARCH_OPERATOR_IN, Value: "1,2,3"},
+		{Field: "id", SearchOperator: search.SEARCH_OPERATOR_NOT_EQUAL, Value: "2"},
+		{Field: "title", SearchOperator: search.SEARCH_OPERATOR_PREFIX, Value: "Go*"},
+	},
+	{
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "1+1=2?"},
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_NOT_CONTAIN, Value: "rain"},
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_FUZZY, Value: "gl"},
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_REGEX, Value: `^go\s+\d+$`},
+	},
+}
+
+// checkGolden compare got with the golden file, the golden file is rewritten with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch, got:\n%s", path, got)
+	}
+}
+
+// translateCases translate every case by translate and marshal the documents
+func translateCases(
+	t *testing.T, cases [][]*search.Searcher, translate func([]*search.Searcher) (search.M, error),
+) []byte {
+	var buf bytes.Buffer
+	for k, searchers := range cases {
+		doc, err := translate(searchers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&buf, "// cases[%d]\n%s\n", k, b)
+	}
+	return buf.Bytes()
+}
+
+func TestSearchToMongo(t *testing.T) {
+	limit, err := search.NewSearcherLimit(&Article{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "search_mongo.golden", translateCases(t, documentCases, limit.ToMongo))
+	_, err = limit.ToMongo([]*search.Searcher{{Field: "title", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "go"}})
+	if err == nil {
+		t.Fatal("match should not be translated")
+	}
+	fmt.Println(err)
+}
+
+func TestSearchToElasticsearch(t *testing.T) {
+	limit, err := search.NewSearcherLimit(&Article{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// match is only supported by elasticsearch
+	cases := append(documentCases, []*search.Searcher{
+		{Field: "title", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "上海 天气"},
+		{Field: "id", SearchOperator: search.SEARCH_OPERATOR_LESS, Value: "100"},
+	})
+	checkGolden(t, "search_elasticsearch.golden", translateCases(t, cases, limit.ToElasticsearch))
+}
+
+func TestSearchToElasticsearchRegexp(t *testing.T) {
+	limit, err := search.NewSearcherLimit(&Article{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// lucene regexp is always anchored and has no \s, \d or ^/$
+	cases := map[string]string{
+		`^go\s+\d+$`: "go[\t-\n\f-\r ]+[0-9]+",
+		`go`:         ".*go.*",
+		`^a|b$`:      "a.*|.*b",
+		`(?i)go$`:    ".*[gG][oO]",
+		`a.b~`:       ".*a[^\n]b\\~.*",
+		`x{2,}y?`:    ".*x{2,}y?.*",
+	}
+	for pattern, want := range cases {
+		query, err := limit.ToElasticsearch([]*search.Searcher{
+			{Field: "body", SearchOperator: search.SEARCH_OPERATOR_REGEX, Value: pattern},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := query["query"].(search.M)["bool"].(search.M)["filter"].([]interface{})[0].(search.M)["regexp"].(search.M)["body"].(search.M)["value"]
+		if got != want {
+			t.Fatalf("%q is translated to %q, want %q", pattern, got, want)
+		}
+	}
+	for _, pattern := range []string{`\bgo`, `(?m)^go`} {
+		_, err = limit.ToElasticsearch([]*search.Searcher{
+			{Field: "body", SearchOperator: search.SEARCH_OPERATOR_REGEX, Value: pattern},
+		})
+		fmt.Println(err)
+		if err == nil {
+			t.Fatalf("%q should not be translated", pattern)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package test
+
+import (
+	"fmt"
+	"go_tests/search"
+	"reflect"
+	"testing"
+)
+
+func TestSearchTokenizer(t *testing.T) {
+	cases := []struct {
+		tokenizer search.Tokenizer
+		text      string
+		want      []string
+	}{
+		{search.WhitespaceTokenizer{}, " Hello  Grpc World ", []string{"hello", "grpc", "world"}},
+		{search.NGramTokenizer{N: 3}, "Hello go", []string{"hel", "ell", "llo", "go"}},
+		{search.BigramTokenizer{}, "上海市Go语言, 人", []string{"上海", "海市", "go", "语言", "人"}},
+	}
+	for k, cs := range cases {
+		got := cs.tokenizer.Tokenize(cs.text)
+		fmt.Printf("cases[%d] %q => %q\n", k, cs.text, got)
+		if !reflect.DeepEqual(got, cs.want) {
+			t.Errorf("cases[%d] got %q, want %q", k, got, cs.want)
+		}
+	}
+}
+
+func TestSearchFullText(t *testing.T) {
+	limit, err := search.NewSearcherLimit(&Article{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	articles := []*Article{
+		{ID: 1, Title: "上海市天气预报", Body: "sunny day in shanghai"},
+		{ID: 2, Title: "北京市天气", Body: "rain rain rain in beijing"},
+		{ID: 3, Title: "上海美食推荐", Body: "food guide, rain or sunny"},
+		{ID: 4, Title: "Go语言教程", Body: "learn go in one day"},
+	}
+	c := search.NewCollection(limit)
+	var all []interface{}
+	for _, article := range articles {
+		if _, err = c.Insert(article); err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, article)
+	}
+	if err = c.AddFullTextIndex("title"); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.AddFullTextIndex("body"); err != nil {
+		t.Fatal(err)
+	}
+
+	searchers := []*search.Searcher{{Field: "title", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "上海"}}
+	plan, err := c.Explain(searchers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan != "full-text index(title) match" {
+		t.Fatalf("unexpected plan %s", plan)
+	}
+	hits, err := c.Search(searchers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 || hits[0].Score <= 0 {
+		t.Fatalf("unexpected hits %v", hits)
+	}
+	// the match without index must find the same datas
+	if err = limit.ValidCheck(searchers); err != nil {
+		t.Fatal(err)
+	}
+	datas, err := searchers[0].Filter(limit, all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(datas) != 2 {
+		t.Fatalf("unexpected filtered datas %v", datas)
+	}
+
+	// "rain" appears three times in the short body of article 2
+	hits, err = c.Search([]*search.Searcher{{Field: "body", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "Rain"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hit := range hits {
+		fmt.Printf("%+v %.4f\n", *hit.Data.(*Article), hit.Score)
+	}
+	if len(hits) != 2 || hits[0].Data.(*Article).ID != 2 || hits[0].Score <= hits[1].Score {
+		t.Fatalf("unexpected hits %v", hits)
+	}
+
+	hits, err = c.Search([]*search.Searcher{
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "sunny day"},
+		{Field: "id", SearchOperator: search.SEARCH_OPERATOR_LESS, Value: "4"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 || hits[0].Data.(*Article).ID != 1 {
+		t.Fatalf("unexpected hits %v", hits)
+	}
+
+	if _, err = limit.SetTokenizer("id", search.WhitespaceTokenizer{}); err == nil {
+		t.Fatal("field id should not support match")
+	}
+}
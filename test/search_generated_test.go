@@ -0,0 +1,220 @@
+package test
+
+import (
+	"fmt"
+	"go_tests/search"
+	"testing"
+)
+
+// the generated matchers must return the same results as the reflective path
+
+func generatedSimpleDatas() []*SimpleStruct {
+	return []*SimpleStruct{
+		{A: 3, B: 30, Str: "wzyao3"},
+		{A: 1, B: 10, Str: "wzyao1"},
+		{A: 5, B: 10, Str: "x"},
+		{A: 2, B: 20, Str: "wzyao2"},
+		{A: 4, B: 40, Str: "wzyao4"},
+	}
+}
+
+func generatedArticles() []*Article {
+	return []*Article{
+		{ID: 1, Title: "上海市天气预报", Body: "sunny day in shanghai"},
+		{ID: 2, Title: "北京市天气", Body: "rain rain rain in beijing"},
+		{ID: 3, Title: "上海美食推荐", Body: "food guide, rain or sunny"},
+		{ID: 4, Title: "Go语言教程", Body: "learn go in one day"},
+	}
+}
+
+func toInterfaces[T any](datas []*T) []interface{} {
+	out := make([]interface{}, len(datas))
+	for i, data := range datas {
+		out[i] = data
+	}
+	return out
+}
+
+func sameDatas[T any](got []*T, want []interface{}) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i].(*T) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSearchGeneratedSimpleStruct(t *testing.T) {
+	datas := generatedSimpleDatas()
+	cases := [][]*search.Searcher{
+		{},
+		{{Field: "a", SearchOperator: search.SEARCH_OPERATOR_LESS_EQUAL, Value: "3"}},
+		{{Field: "a", SearchOperator: search.SEARCH_OPERATOR_IN, Value: "1, 4,9"}},
+		{{Field: "a", SearchOperator: search.SEARCH_OPERATOR_NOT_EQUAL, Value: "abc"}}, // converted to 0
+		{
+			{Field: "b", SearchOperator: search.SEARCH_OPERATOR_GREATER, Value: "10"},
+			{Field: "str", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "yao"},
+		},
+		{{Field: "str", SearchOperator: search.SEARCH_OPERATOR_NOT_CONTAIN, Value: "wz"}},
+		{{Field: "str", SearchOperator: search.SEARCH_OPERATOR_IN, Value: "x,wzyao2"}},
+		// invalid searchers
+		{{Field: "b", SearchOperator: search.SEARCH_OPERATOR_IN, Value: "1"}},
+		{{Field: "str_a", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "1"}},
+	}
+	for k, searchers := range cases {
+		var want []interface{}
+		query, wantErr := searchLimit.Compile(searchers)
+		if wantErr == nil {
+			want, wantErr = query.Filter(toInterfaces(datas))
+		}
+		var got []*SimpleStruct
+		m, err := NewSimpleStructMatcher(searchers)
+		if err == nil {
+			got, err = m.Filter(datas)
+		}
+		fmt.Printf("cases[%d] %d datas, %v\n", k, len(got), err)
+		if fmt.Sprint(err) != fmt.Sprint(wantErr) {
+			t.Fatalf("cases[%d] err = %v, want %v", k, err, wantErr)
+		}
+		if !sameDatas(got, want) {
+			t.Fatalf("cases[%d] got %v, want %v", k, got, want)
+		}
+		if fmt.Sprint(ValidateSimpleStructSearchers(searchers)) != fmt.Sprint(wantErr) {
+			t.Fatalf("cases[%d] validator is not consistent", k)
+		}
+	}
+	if _, err := NewSimpleStructMatcher(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSimpleStructMatcher([]*search.Searcher{nil}); err == nil {
+		t.Fatal("nil searcher should fail")
+	}
+
+	for _, field := range []string{"a", "b", "str", "str_a"} {
+		for _, desc := range []bool{false, true} {
+			got, want := generatedSimpleDatas(), toInterfaces(generatedSimpleDatas())
+			err, wantErr := SortSimpleStruct(got, field, desc), searchLimit.Sort(want, field, desc)
+			if fmt.Sprint(err) != fmt.Sprint(wantErr) {
+				t.Fatalf("sort %s err = %v, want %v", field, err, wantErr)
+			}
+			for i := range got {
+				if *got[i] != *want[i].(*SimpleStruct) {
+					t.Fatalf("sort %s desc(%v) [%d] = %v, want %v", field, desc, i, got[i], want[i])
+				}
+			}
+		}
+	}
+}
+
+func TestSearchGeneratedArticle(t *testing.T) {
+	limit, err := search.NewSearcherLimit(&Article{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	articles := generatedArticles()
+	cases := [][]*search.Searcher{
+		{{Field: "title", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "上海 天气"}},
+		{{Field: "title", SearchOperator: search.SEARCH_OPERATOR_FUZZY, Value: "上天"}},
+		{{Field: "title", SearchOperator: search.SEARCH_OPERATOR_PREFIX, Value: "Go"}},
+		{{Field: "body", SearchOperator: search.SEARCH_OPERATOR_REGEX, Value: `^(rain|food)\b`}},
+		{{Field: "body", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "Rain"}},
+		{
+			{Field: "id", SearchOperator: search.SEARCH_OPERATOR_GREATER_EQUAL, Value: "2"},
+			{Field: "body", SearchOperator: search.SEARCH_OPERATOR_NOT_CONTAIN, Value: "rain"},
+		},
+		{{Field: "body", SearchOperator: search.SEARCH_OPERATOR_REGEX, Value: "("}},
+	}
+	for k, searchers := range cases {
+		var want []interface{}
+		query, wantErr := limit.Compile(searchers)
+		if wantErr == nil {
+			want, wantErr = query.Filter(toInterfaces(articles))
+		}
+		var got []*Article
+		m, err := NewArticleMatcher(searchers)
+		if err == nil {
+			got, err = m.Filter(articles)
+		}
+		fmt.Printf("cases[%d] %d datas, %v\n", k, len(got), err)
+		if fmt.Sprint(err) != fmt.Sprint(wantErr) {
+			t.Fatalf("cases[%d] err = %v, want %v", k, err, wantErr)
+		}
+		if !sameDatas(got, want) {
+			t.Fatalf("cases[%d] got %v, want %v", k, got, want)
+		}
+	}
+}
+
+func TestSearchGeneratedNamedTypes(t *testing.T) {
+	limit, err := search.NewSearcherLimit(&Ticket{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets := []*Ticket{
+		{Status: 1, Label: "bug-ui", Level: 3},
+		{Status: 2, Label: "feature", Level: 1},
+		{Status: 3, Label: "bug-db", Level: 5},
+	}
+	cases := [][]*search.Searcher{
+		{{Field: "status", SearchOperator: search.SEARCH_OPERATOR_GREATER, Value: "1"}},
+		{{Field: "status", SearchOperator: search.SEARCH_OPERATOR_IN, Value: "1,3"}},
+		{{Field: "label", SearchOperator: search.SEARCH_OPERATOR_PREFIX, Value: "bug"}},
+		{
+			{Field: "label", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "bug-db"},
+			{Field: "level", SearchOperator: search.SEARCH_OPERATOR_GREATER_EQUAL, Value: "5"},
+		},
+	}
+	for k, searchers := range cases {
+		query, err := limit.Compile(searchers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := query.Filter(toInterfaces(tickets))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := NewTicketMatcher(searchers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := m.Filter(tickets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Printf("cases[%d] %d datas\n", k, len(got))
+		if len(got) == 0 || !sameDatas(got, want) {
+			t.Fatalf("cases[%d] got %v, want %v", k, got, want)
+		}
+	}
+}
+
+func BenchmarkSearchReflectFilter(b *testing.B) {
+	b.ReportAllocs()
+	datas := toInterfaces(generatedSimpleDatas())
+	query, err := searchLimit.Compile(parallelSearchers())
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		if _, err = query.Filter(datas); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSearchGeneratedFilter(b *testing.B) {
+	b.ReportAllocs()
+	datas := generatedSimpleDatas()
+	m, err := NewSimpleStructMatcher(parallelSearchers())
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		if _, err = m.Filter(datas); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
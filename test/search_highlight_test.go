@@ -0,0 +1,110 @@
+package test
+
+import (
+	"fmt"
+	"go_tests/search"
+	"strings"
+	"testing"
+)
+
+func TestSearchHighlight(t *testing.T) {
+	limit, err := search.NewSearcherLimit(&Article{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	datasIn := []interface{}{
+		&Article{ID: 1, Title: "上海市天气预报, 上海明天晴", Body: "Go is fun, go go go"},
+	}
+	hits, err := limit.Rank([]*search.Searcher{
+		{Field: "title", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "上海市"},
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_REGEX, Value: `[Gg]o\b`},
+	}, datasIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("unexpected hits %v", hits)
+	}
+	fmt.Printf("%+v\n", hits[0].Highlights)
+	title := hits[0].Highlights["title"]
+	// 上海 and 海市 are merged, the second 上海 is found as well
+	if len(title) != 2 || title[0] != (search.Highlight{Start: 0, End: 9, RuneStart: 0, RuneEnd: 3}) ||
+		title[1] != (search.Highlight{Start: 23, End: 29, RuneStart: 9, RuneEnd: 11}) {
+		t.Fatalf("unexpected title highlights %+v", title)
+	}
+	if len(hits[0].Highlights["body"]) != 4 {
+		t.Fatalf("unexpected body highlights %+v", hits[0].Highlights["body"])
+	}
+
+	article := datasIn[0].(*Article)
+	snippet := search.Snippet(article.Title, title, search.SnippetOptions{})
+	fmt.Println(snippet)
+	if snippet != "<em>上海市</em>天气预报, <em>上海</em>明天晴" {
+		t.Fatalf("unexpected snippet %s", snippet)
+	}
+	snippet = search.Snippet(article.Title, title, search.SnippetOptions{PreTag: "[", PostTag: "]", Context: 2})
+	fmt.Println(snippet)
+	if snippet != "[上海市]天气..., [上海]明天..." {
+		t.Fatalf("unexpected snippet %s", snippet)
+	}
+
+	hits, err = limit.Rank([]*search.Searcher{
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "go"},
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_PREFIX, Value: "Go is"},
+	}, datasIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := hits[0].Highlights["body"]
+	snippet = search.Snippet(article.Body, body, search.SnippetOptions{})
+	fmt.Println(snippet)
+	if snippet != "<em>Go is</em> fun, <em>go</em> <em>go</em> <em>go</em>" {
+		t.Fatalf("unexpected snippet %s", snippet)
+	}
+
+	if err = limit.ValidCheck([]*search.Searcher{
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_REGEX, Value: "(go"},
+	}); err == nil {
+		t.Fatal("invalid regex should not pass the check")
+	}
+	fmt.Println(err)
+}
+
+func TestSearchHighlightTokens(t *testing.T) {
+	limit, err := search.NewSearcherLimit(&Article{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	datasIn := []interface{}{&Article{ID: 1, Body: "Gopher GO going, go"}}
+	// only the whole tokens of the whitespace tokenizer are highlighted, not the go of Gopher
+	hits, err := limit.Rank([]*search.Searcher{
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "go"},
+	}, datasIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snippet := search.Snippet(datasIn[0].(*Article).Body, hits[0].Highlights["body"], search.SnippetOptions{})
+	fmt.Println(snippet)
+	if snippet != "Gopher <em>GO</em> going, <em>go</em>" {
+		t.Fatalf("unexpected snippet %s", snippet)
+	}
+
+	// a tokenizer without positions falls back to finding the terms ignoring case,
+	// "ſ" folds to "s" with a different byte length
+	tokenizer := search.TokenizerFunc(func(text string) []string {
+		return []string{strings.ToLower(strings.ReplaceAll(text, "ſ", "s"))}
+	})
+	if limit, err = limit.SetTokenizer("body", tokenizer); err != nil {
+		t.Fatal(err)
+	}
+	datasIn = []interface{}{&Article{ID: 2, Body: "ſ"}, &Article{ID: 3, Body: "xSx"}}
+	hits, err = limit.Rank([]*search.Searcher{
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "ſ"},
+	}, datasIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 || hits[0].Highlights["body"][0] != (search.Highlight{Start: 0, End: 2, RuneStart: 0, RuneEnd: 1}) {
+		t.Fatalf("unexpected hits %+v", hits)
+	}
+}
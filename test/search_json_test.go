@@ -0,0 +1,135 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"go_tests/search"
+	"reflect"
+	"testing"
+)
+
+func TestSearchFilterJSON(t *testing.T) {
+	docs := [][]byte{
+		[]byte(`{"a": 1, "extra": {"a": 100, "list": [1, "}", {"x": null}]}, "b": 10, "str": "wzyao1"}`),
+		[]byte(`{"str_a": "x", "b": 20, "a": 2, "str": "wz\"yao2"}`),
+		[]byte(`{"a": 3.5, "b": 30, "str": "wzyao3"}`),
+		[]byte(`{"a": null, "b": 40, "str": "wzyao4"}`),
+		[]byte(`{"b": 50, "str": "other", "a": 5}`),
+	}
+	searchers := []*search.Searcher{
+		{Field: "a", SearchOperator: search.SEARCH_OPERATOR_LESS_EQUAL, Value: "3"},
+		{Field: "str", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "yao"},
+	}
+	docsOut, err := searchLimit.FilterJSON(searchers, docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range docsOut {
+		fmt.Println(string(doc))
+	}
+	// 3.5 can not be converted to int and null does not match
+	if len(docsOut) != 2 || string(docsOut[0]) != string(docs[0]) || string(docsOut[1]) != string(docs[1]) {
+		t.Fatalf("unexpected docs %q", docsOut)
+	}
+
+	docsOut, err = searchLimit.FilterJSON([]*search.Searcher{
+		{Field: "str", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: `wz"yao2`},
+	}, docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docsOut) != 1 || string(docsOut[0]) != string(docs[1]) {
+		t.Fatalf("escaped string is not matched %q", docsOut)
+	}
+
+	if _, err = searchLimit.FilterJSON(searchers, [][]byte{[]byte(`{"a": 1,`)}); err == nil {
+		t.Fatal("invalid json should fail")
+	}
+	fmt.Println(err)
+
+	// the rows decoded by encoding/json have float64 numbers
+	var rows []map[string]interface{}
+	for _, doc := range docs {
+		var row map[string]interface{}
+		if err = json.Unmarshal(doc, &row); err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+	rowsOut, err := searchLimit.FilterMaps([]*search.Searcher{
+		{Field: "b", SearchOperator: search.SEARCH_OPERATOR_GREATER, Value: "20"},
+		{Field: "str", SearchOperator: search.SEARCH_OPERATOR_IN, Value: "wzyao3,other"},
+	}, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(rowsOut)
+	if len(rowsOut) != 2 || rowsOut[0]["b"] != float64(30) || rowsOut[1]["b"] != float64(50) {
+		t.Fatalf("unexpected rows %v", rowsOut)
+	}
+}
+
+func TestSearchFilterJSONConsistent(t *testing.T) {
+	docs := [][]byte{
+		[]byte(`{"a": 3.5, "str": "x"}`),
+		[]byte(`{"a": 3.0, "str": "x"}`),
+		[]byte(`{"a": 1, "a": 1, "str": "x"}`),
+		[]byte(`{"a": -2e0, "str": "x"}`),
+		// the last value of a repeated key wins like encoding/json
+		[]byte(`{"a": 1, "str": "x", "a": 4}`),
+		[]byte(`{"a": 4, "str": "x", "a": 2}`),
+	}
+	searchers := []*search.Searcher{
+		{Field: "a", SearchOperator: search.SEARCH_OPERATOR_LESS_EQUAL, Value: "3"},
+		{Field: "str", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "x"},
+	}
+	docsOut, err := searchLimit.FilterJSON(searchers, docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]interface{}
+	for _, doc := range docs {
+		var row map[string]interface{}
+		if err = json.Unmarshal(doc, &row); err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+	rowsOut, err := searchLimit.FilterMaps(searchers, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("%q %v\n", docsOut, rowsOut)
+	// 3.5 is not truncated to 3 and the repeated key does not stop the extraction before str
+	if len(docsOut) != 4 || len(rowsOut) != 4 {
+		t.Fatalf("unexpected docs %q and rows %v", docsOut, rowsOut)
+	}
+	for k, index := range []int{1, 2, 3, 5} {
+		if string(docsOut[k]) != string(docs[index]) || !reflect.DeepEqual(rowsOut[k], rows[index]) {
+			t.Fatalf("docs[%d] is not consistent with rows", index)
+		}
+	}
+
+	for _, doc := range []string{`{"a": tru, "str": "x"}`, `{"a": 01, "str": "x"}`, `{"a": 1., "str": "x"}`, `{"b": -, "a": 1}`,
+		`{"a": 2, garbage`, `{"a": 2, "str": "x"} trailing`, `{"a": 2, "str": "x", "b": [1,}`,
+	} {
+		if _, err = searchLimit.FilterJSON(searchers, [][]byte{[]byte(doc)}); err == nil {
+			t.Fatalf("%s should be invalid json", doc)
+		}
+	}
+}
+
+func BenchmarkSearchFilterJSON(b *testing.B) {
+	b.ReportAllocs()
+	doc := []byte(`{"a": 1, "b": 10, "str": "wzyao1", "str_a": "a long string which is skipped", "extra": {"k": [1, 2, 3]}}`)
+	docs := [][]byte{doc, doc, doc, doc, doc}
+	for i := 0; i < b.N; i++ {
+		searchers := []*search.Searcher{
+			{Field: "a", SearchOperator: search.SEARCH_OPERATOR_LESS_EQUAL, Value: "3"},
+			{Field: "str", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "wzyao"},
+		}
+		if _, err := searchLimit.FilterJSON(searchers, docs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
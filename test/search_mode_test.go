@@ -0,0 +1,56 @@
+package test
+
+import (
+	"testing"
+)
+
+// Run the benchmarks with and without the search_safe tag to compare the field access modes,
+// the results are recorded in search/access_safe.go
+
+func BenchmarkSearchModeFilter(b *testing.B) {
+	b.ReportAllocs()
+	datas := toInterfaces(generatedSimpleDatas())
+	query, err := searchLimit.Compile(parallelSearchers())
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		if _, err = query.Filter(datas); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSearchModeProject(b *testing.B) {
+	b.ReportAllocs()
+	datas := toInterfaces(generatedSimpleDatas())
+	var dst []byte
+	for i := 0; i < b.N; i++ {
+		for _, data := range datas {
+			var err error
+			if dst, err = searchLimit.AppendJSON(dst[:0], []string{"a", "b", "str"}, data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkSearchModeDistinctRows(b *testing.B) {
+	b.ReportAllocs()
+	datas := toInterfaces(generatedSimpleDatas())
+	for i := 0; i < b.N; i++ {
+		if _, err := searchLimit.DistinctRows([]string{"b", "str"}, datas); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSearchModeSort(b *testing.B) {
+	b.ReportAllocs()
+	datas := toInterfaces(generatedSimpleDatas())
+	for i := 0; i < b.N; i++ {
+		if err := searchLimit.Sort(datas, "a", i%2 == 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
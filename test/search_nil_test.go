@@ -0,0 +1,59 @@
+package test
+
+import (
+	"fmt"
+	"go_tests/search"
+	"testing"
+	"unsafe"
+)
+
+func TestSearchIsNil(t *testing.T) {
+	var ptr *SimpleStruct
+	var m map[string]int
+	var s []int
+	var f func()
+	var c chan int
+	var e error
+	var up unsafe.Pointer
+	cases := []struct {
+		v      interface{}
+		expect bool
+	}{
+		{nil, true}, {ptr, true}, {m, true}, {s, true}, {f, true}, {c, true}, {e, true}, {up, true},
+		{&SimpleStruct{}, false}, {map[string]int{}, false}, {[]int{}, false}, {func() {}, false},
+		{make(chan int), false}, {SimpleStruct{}, false}, {0, false}, {"", false},
+	}
+	for k, c := range cases {
+		if search.IsNil(c.v) != c.expect {
+			t.Fatalf("case %d: IsNil(%#v) should be %v", k, c.v, c.expect)
+		}
+	}
+}
+
+func TestSearchFilterNilData(t *testing.T) {
+	searchers := []*search.Searcher{{Field: "a", SearchOperator: search.SEARCH_OPERATOR_GREATER, Value: "0"}}
+	if err := searchLimit.ValidCheck(searchers); err != nil {
+		t.Fatal(err)
+	}
+	var ptr *SimpleStruct
+	cases := []struct {
+		datasIn []interface{}
+		expect  string
+	}{
+		// only the first data was checked against nil before
+		{[]interface{}{&SimpleStruct{A: 1}, nil}, "datasIn[1] is nil"},
+		{[]interface{}{&SimpleStruct{A: 1}, &SimpleStruct{A: 2}, ptr}, "datasIn[2] is a nil pointer"},
+		{[]interface{}{&SimpleStruct{A: 1}, SimpleStruct{A: 2}}, "datasIn[1]'s type is invalid"},
+		{[]interface{}{&SimpleStruct{A: 1}, 1}, "datasIn[1]'s type is invalid"},
+	}
+	for k, c := range cases {
+		_, err := searchers[0].Filter(searchLimit, c.datasIn)
+		fmt.Println(k, err)
+		if err == nil || err.Error() != c.expect {
+			t.Fatalf("case %d: expect error %q, got %v", k, c.expect, err)
+		}
+		if _, err = searchLimit.Project([]string{"a"}, c.datasIn); err == nil || err.Error() != c.expect {
+			t.Fatalf("case %d: expect error %q from Project, got %v", k, c.expect, err)
+		}
+	}
+}
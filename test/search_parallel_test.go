@@ -0,0 +1,86 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"go_tests/search"
+	"strconv"
+	"testing"
+)
+
+func parallelDatas(n int) []interface{} {
+	datas := make([]interface{}, n)
+	for i := range datas {
+		datas[i] = &SimpleStruct{A: i, B: i % 100, Str: "wzyao" + strconv.Itoa(i%10)}
+	}
+	return datas
+}
+
+func parallelSearchers() []*search.Searcher {
+	return []*search.Searcher{
+		{Field: "b", SearchOperator: search.SEARCH_OPERATOR_LESS, Value: "50"},
+		{Field: "str", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "wzyao3"},
+	}
+}
+
+func TestSearchFilterParallel(t *testing.T) {
+	datas := parallelDatas(100000)
+	serial, err := searchLimit.FilterParallel(context.Background(), parallelSearchers(), datas, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, workers := range []int{0, 2, 3, 7, 64} {
+		datasOut, err := searchLimit.FilterParallel(context.Background(), parallelSearchers(), datas, workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println(workers, len(datasOut))
+		if len(datasOut) != len(serial) {
+			t.Fatalf("workers(%d) got %d datas, want %d", workers, len(datasOut), len(serial))
+		}
+		for i := range datasOut {
+			if datasOut[i] != serial[i] {
+				t.Fatalf("workers(%d) datasOut[%d] is out of order", workers, i)
+			}
+		}
+	}
+
+	datas[60000] = nil
+	if _, err = searchLimit.FilterParallel(context.Background(), parallelSearchers(), datas, 4); err == nil ||
+		err.Error() != "datasIn[60000] is nil" {
+		t.Fatalf("err = %v", err)
+	}
+	// the invalid data of the smallest index is reported whichever chunk fails first
+	datas[75000] = nil
+	for i := 0; i < 20; i++ {
+		if _, err = searchLimit.FilterParallel(context.Background(), parallelSearchers(), datas, 4); err == nil ||
+			err.Error() != "datasIn[60000] is nil" {
+			t.Fatalf("err = %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = searchLimit.FilterParallel(ctx, parallelSearchers(), parallelDatas(100000), 4); err != context.Canceled {
+		t.Fatalf("err = %v", err)
+	}
+	// small slices are filtered serially and still honor ctx
+	if _, err = searchLimit.FilterParallel(ctx, parallelSearchers(), parallelDatas(10), 4); err != context.Canceled {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func benchmarkFilterParallel(b *testing.B, n, workers int) {
+	b.ReportAllocs()
+	datas := parallelDatas(n)
+	for i := 0; i < b.N; i++ {
+		if _, err := searchLimit.FilterParallel(context.Background(), parallelSearchers(), datas, workers); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSearchFilterSerial1M(b *testing.B)   { benchmarkFilterParallel(b, 1000000, 1) }
+func BenchmarkSearchFilterParallel1M(b *testing.B) { benchmarkFilterParallel(b, 1000000, 0) }
+func BenchmarkSearchFilterSerial1K(b *testing.B)   { benchmarkFilterParallel(b, 1000, 1) }
+func BenchmarkSearchFilterParallel1K(b *testing.B) { benchmarkFilterParallel(b, 1000, 0) }
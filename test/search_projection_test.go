@@ -0,0 +1,74 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go_tests/search"
+	"math"
+	"testing"
+)
+
+func TestSearchProject(t *testing.T) {
+	datasIn := []interface{}{
+		&SimpleStruct{A: 1, B: 10, Str: "wzyao1"},
+		&SimpleStruct{A: 2, B: 20, Str: "wz\"yao\n2"},
+	}
+	datasOut, err := searchLimit.Project([]string{"a", "str"}, datasIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(datasOut)
+	if len(datasOut) != 2 || datasOut[1]["a"] != 2 || datasOut[1]["str"] != "wz\"yao\n2" {
+		t.Fatalf("unexpected projection %v", datasOut)
+	}
+	if _, ok := datasOut[0]["b"]; ok {
+		t.Fatal("field b should not be projected")
+	}
+
+	var buf bytes.Buffer
+	if err = searchLimit.ProjectJSON(&buf, []string{"a", "str"}, datasIn); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(buf.String())
+	var decoded []map[string]interface{}
+	if err = json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid json %s: %s", buf.String(), err)
+	}
+	if len(decoded) != 2 || decoded[1]["str"] != "wz\"yao\n2" || decoded[0]["a"] != float64(1) {
+		t.Fatalf("unexpected json projection %v", decoded)
+	}
+
+	// nothing is written when a data in the middle can not be encoded
+	wideLimit, err := search.NewSearcherLimit(&WideStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	err = wideLimit.ProjectJSON(&buf, []string{"a", "b"}, []interface{}{
+		&WideStruct{A: 1, B: 0.5}, &WideStruct{A: 2, B: math.NaN()}, &WideStruct{A: 3},
+	})
+	fmt.Println(err)
+	if err == nil || err.Error() != "datasIn[1]'s field(b) is an unsupported float value: NaN" || buf.Len() != 0 {
+		t.Fatalf("unexpected error %v with output %s", err, buf.String())
+	}
+
+	// str_a is not declared by search tag
+	if _, err = searchLimit.Project([]string{"str_a"}, datasIn); err == nil {
+		t.Fatal("field str_a should not be allowed")
+	}
+	fmt.Println(err)
+}
+
+func BenchmarkSearchProjectJSON(b *testing.B) {
+	b.ReportAllocs()
+	data := &SimpleStruct{A: 1, B: 10, Str: "wzyao1"}
+	fields := []string{"a", "b", "str"}
+	buf := make([]byte, 0, 128)
+	var err error
+	for i := 0; i < b.N; i++ {
+		if buf, err = searchLimit.AppendJSON(buf[:0], fields, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
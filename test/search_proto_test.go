@@ -0,0 +1,80 @@
+package test
+
+import (
+	"fmt"
+	"go_tests/pb"
+	"go_tests/search"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSearchFilterMessages(t *testing.T) {
+	limit, err := search.NewSearcherLimitFromDescriptor((&pb.User{}).ProtoReflect().Descriptor())
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := []proto.Message{
+		&pb.User{Id: 1, Name: "wzyao", Tags: []string{"go", "grpc"}, Score: 90,
+			Address: &pb.Address{City: "shanghai", Zip: 200000}, Status: pb.UserStatus_USER_STATUS_ACTIVE},
+		&pb.User{Id: 2, Name: "tom", Tags: []string{"rust"}, Score: 60,
+			History: []*pb.Address{{City: "beijing", Zip: 100000}, {City: "shanghai", Zip: 200001}}},
+		&pb.User{Id: 3, Name: "wzyao2", Score: 75, Address: &pb.Address{City: "shenzhen", Zip: 518000},
+			Status: pb.UserStatus_USER_STATUS_ARCHIVED},
+	}
+	cases := []struct {
+		searchers []*search.Searcher
+		ids       string
+	}{
+		{[]*search.Searcher{{Field: "name", SearchOperator: search.SEARCH_OPERATOR_PREFIX, Value: "wzyao"}}, "[1 3]"},
+		{[]*search.Searcher{{Field: "tags", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "grpc"}}, "[1]"},
+		{[]*search.Searcher{{Field: "address.city", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "sh"}}, "[1 3]"},
+		{[]*search.Searcher{{Field: "history.city", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "shanghai"}}, "[2]"},
+		{[]*search.Searcher{{Field: "address.zip", SearchOperator: search.SEARCH_OPERATOR_LESS, Value: "300000"}}, "[1]"},
+		{[]*search.Searcher{
+			{Field: "score", SearchOperator: search.SEARCH_OPERATOR_GREATER_EQUAL, Value: "70"},
+			{Field: "status", SearchOperator: search.SEARCH_OPERATOR_IN, Value: "1,2"},
+		}, "[1 3]"},
+		{[]*search.Searcher{{Field: "status", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "0"}}, "[2]"},
+	}
+	for k, cs := range cases {
+		msgs, err := limit.FilterMessages(cs.searchers, users)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int64
+		for _, msg := range msgs {
+			ids = append(ids, msg.(*pb.User).Id)
+		}
+		fmt.Printf("cases[%d] %v\n", k, ids)
+		if fmt.Sprint(ids) != cs.ids {
+			t.Errorf("cases[%d] got %v, want %s", k, ids, cs.ids)
+		}
+	}
+
+	// remark has no search option
+	if _, err = limit.FilterMessages([]*search.Searcher{
+		{Field: "remark", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "x"},
+	}, users); err == nil {
+		t.Fatal("field remark should not support search")
+	}
+	fmt.Println(err)
+	if _, err = limit.FilterMessages(nil, []proto.Message{&pb.SayHelloResp{}}); err == nil {
+		t.Fatal("message type should be checked")
+	}
+	fmt.Println(err)
+
+	helloLimit, err := search.NewSearcherLimitFromDescriptor((&pb.SayHelloResp{}).ProtoReflect().Descriptor())
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := helloLimit.FilterMessages([]*search.Searcher{
+		{Field: "message", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "Grpc"},
+	}, []proto.Message{&pb.SayHelloResp{Message: "Hello, Grpc!"}, &pb.SayHelloResp{Message: "Hello"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("unexpected messages %v", msgs)
+	}
+}
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"go_tests/search"
+	"reflect"
+	"testing"
+)
+
+func TestSearchParseQuery(t *testing.T) {
+	cases := []struct {
+		query string
+		want  []search.Searcher
+	}{
+		{"", nil},
+		{"age>=18", []search.Searcher{{Field: "age", SearchOperator: search.SEARCH_OPERATOR_GREATER_EQUAL, Value: "18"}}},
+		{
+			`age >= 18 and name contain "wz \"yao\"" && city in shanghai,beijing`,
+			[]search.Searcher{
+				{Field: "age", SearchOperator: search.SEARCH_OPERATOR_GREATER_EQUAL, Value: "18"},
+				{Field: "name", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: `wz "yao"`},
+				{Field: "city", SearchOperator: search.SEARCH_OPERATOR_IN, Value: "shanghai,beijing"},
+			},
+		},
+		{
+			`name != 'a\b' AND name !~ x and score < -1.5 and name regex '^t.*m$'`,
+			[]search.Searcher{
+				{Field: "name", SearchOperator: search.SEARCH_OPERATOR_NOT_EQUAL, Value: `a\b`},
+				{Field: "name", SearchOperator: search.SEARCH_OPERATOR_NOT_CONTAIN, Value: "x"},
+				{Field: "score", SearchOperator: search.SEARCH_OPERATOR_LESS, Value: "-1.5"},
+				{Field: "name", SearchOperator: search.SEARCH_OPERATOR_REGEX, Value: "^t.*m$"},
+			},
+		},
+	}
+	for _, c := range cases {
+		searchers, err := search.ParseQuery(c.query)
+		if err != nil {
+			t.Fatal(c.query, err)
+		}
+		fmt.Println(c.query, len(searchers))
+		if len(searchers) != len(c.want) {
+			t.Fatalf("query(%s) got %d searchers", c.query, len(searchers))
+		}
+		for i, searcher := range searchers {
+			if *searcher != c.want[i] {
+				t.Errorf("query(%s) searchers[%d] = %+v, want %+v", c.query, i, *searcher, c.want[i])
+			}
+		}
+	}
+
+	for _, query := range []string{
+		"age",
+		"age >=",
+		"age between 1",
+		`name eq "tom`,
+		"age > 1 or age < 0",
+		"age > 1 and",
+		"> 1",
+	} {
+		_, err := search.ParseQuery(query)
+		fmt.Println(query, err)
+		if err == nil {
+			t.Errorf("query(%s) should be invalid", query)
+		}
+	}
+}
+
+func TestSearchInferSchema(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"id": "1", "price": "9.5", "name": "apple", "stock": json.Number("10"), "tags": []interface{}{"a"}},
+		{"id": "2", "price": "10", "name": "100", "stock": "", "active": true},
+		{"id": "3", "price": 12.0, "name": nil, "stock": 7.0},
+	}
+	schema := search.InferSchema(rows)
+	types := make(map[string]string)
+	for _, field := range schema.Fields {
+		types[field.Name] = field.Type
+	}
+	fmt.Println(types)
+	want := map[string]string{"active": "string", "id": "int64", "name": "string", "price": "float64", "stock": "int64"}
+	if !reflect.DeepEqual(types, want) {
+		t.Fatalf("types = %v, want %v", types, want)
+	}
+
+	limit, err := search.NewSearcherLimitFromSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	searchers, err := search.ParseQuery("price >= 10 and name prefix 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rowsOut, err := limit.FilterMaps(searchers, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rowsOut) != 1 || rowsOut[0]["id"] != "2" {
+		t.Fatalf("rowsOut = %v", rowsOut)
+	}
+	query, err := limit.Compile(searchers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !query.MatchMap(rows[1]) || query.MatchMap(rows[2]) {
+		t.Fatal("MatchMap is not consistent with FilterMaps")
+	}
+	// the wrapper compiles the searchers without modifying them
+	if !limit.MatchMap(searchers, rows[1]) || limit.MatchMap(searchers, rows[2]) {
+		t.Fatal("SearcherLimit.MatchMap is not consistent with Query.MatchMap")
+	}
+	if limit.MatchMap([]*search.Searcher{{Field: "unknown", Value: "1"}}, rows[1]) {
+		t.Fatal("invalid searchers should match no row")
+	}
+}
+
+func TestSearchInferSchemaDecimal(t *testing.T) {
+	rows := []map[string]interface{}{{"code": "08"}, {"code": "010"}, {"code": "9"}}
+	schema := search.InferSchema(rows)
+	if len(schema.Fields) != 1 || schema.Fields[0].Type != "int64" {
+		t.Fatalf("unexpected schema %+v", schema)
+	}
+	limit, err := search.NewSearcherLimitFromSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the values are parsed in base 10 when they are matched, the same as they are inferred
+	for value, want := range map[string]string{"8": "08", "10": "010", "9": "9"} {
+		rowsOut, err := limit.FilterMaps([]*search.Searcher{
+			{Field: "code", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: value},
+		}, rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rowsOut) != 1 || rowsOut[0]["code"] != want {
+			t.Fatalf("code eq %s: rowsOut = %v, want %s", value, rowsOut, want)
+		}
+	}
+}
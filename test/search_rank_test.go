@@ -0,0 +1,90 @@
+package test
+
+import (
+	"fmt"
+	"go_tests/search"
+	"testing"
+)
+
+func TestSearchRank(t *testing.T) {
+	limit, err := search.NewSearcherLimit(&Article{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	datasIn := []interface{}{
+		&Article{ID: 1, Title: "learn golang", Body: "go"},
+		&Article{ID: 2, Title: "go", Body: "learn go"},
+		&Article{ID: 3, Title: "golang", Body: "go in action"},
+		&Article{ID: 4, Title: "Great Ocean", Body: "ocean"},
+		&Article{ID: 5, Title: "rust", Body: "go"},
+	}
+	// exact(2) > prefix(3) > contain(1) > fuzzy(4)
+	hits, err := limit.Rank([]*search.Searcher{
+		{Field: "title", SearchOperator: search.SEARCH_OPERATOR_FUZZY, Value: "go"},
+	}, datasIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, hit := range hits {
+		fmt.Printf("%+v %.2f\n", *hit.Data.(*Article), hit.Score)
+		ids = append(ids, hit.Data.(*Article).ID)
+	}
+	if fmt.Sprint(ids) != "[2 3 1 4]" || hits[0].Score != 2 {
+		t.Fatalf("unexpected rank %v", ids)
+	}
+
+	// the strings are scored ignoring case, so "Go" is an exact hit of "GO" rather than a fuzzy one
+	hits, err = limit.Rank([]*search.Searcher{
+		{Field: "title", SearchOperator: search.SEARCH_OPERATOR_FUZZY, Value: "GO"},
+	}, []interface{}{
+		&Article{ID: 1, Title: "Great Ocean"},
+		&Article{ID: 2, Title: "ergo"},
+		&Article{ID: 3, Title: "Golang"},
+		&Article{ID: 4, Title: "Go"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids = ids[:0]
+	for _, hit := range hits {
+		fmt.Printf("%+v %.2f\n", *hit.Data.(*Article), hit.Score)
+		ids = append(ids, hit.Data.(*Article).ID)
+	}
+	if fmt.Sprint(ids) != "[4 3 2 1]" {
+		t.Fatalf("unexpected rank %v", ids)
+	}
+
+	// the range operator of id only filters and does not change the score
+	hits, err = limit.Rank([]*search.Searcher{
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "go"},
+		{Field: "id", SearchOperator: search.SEARCH_OPERATOR_LESS, Value: "5"},
+	}, datasIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids = ids[:0]
+	for _, hit := range hits {
+		ids = append(ids, hit.Data.(*Article).ID)
+	}
+	if fmt.Sprint(ids) != "[1 3 2]" {
+		t.Fatalf("unexpected rank %v", ids)
+	}
+
+	// boost is applied in Collection.Search as well
+	c := search.NewCollection(limit)
+	for _, data := range datasIn {
+		if _, err = c.Insert(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	collectionHits, err := c.Search([]*search.Searcher{
+		{Field: "title", SearchOperator: search.SEARCH_OPERATOR_PREFIX, Value: "go"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collectionHits) != 2 || collectionHits[0].Data.(*Article).ID != 2 || collectionHits[1].Score != 1.5 {
+		t.Fatalf("unexpected hits %v", collectionHits)
+	}
+}
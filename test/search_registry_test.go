@@ -0,0 +1,147 @@
+package test
+
+import (
+	"fmt"
+	"go_tests/search"
+	"sync"
+	"testing"
+)
+
+// RegistryStruct is only used by the registry test, so its limit is built there
+type RegistryStruct struct {
+	ID   int    `json:"id" search:"eq,in"`
+	Name string `json:"name" search:"contain"`
+}
+
+func TestSearchLimitRegistry(t *testing.T) {
+	limits := make([]*search.SearcherLimit, 32)
+	var wg sync.WaitGroup
+	for g := range limits {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			limit, err := search.LimitFor[RegistryStruct]()
+			if err != nil {
+				t.Error(err)
+			}
+			limits[g] = limit
+		}(g)
+	}
+	wg.Wait()
+	for g, limit := range limits {
+		if limit == nil || limit != limits[0] {
+			t.Fatalf("limits[%d] is not the cached limit", g)
+		}
+	}
+	if limit, err := search.LimitOf(&RegistryStruct{}); err != nil || limit != limits[0] {
+		t.Fatalf("LimitOf(pointer) = %p, %v", limit, err)
+	}
+	if limit, err := search.LimitOf(RegistryStruct{}); err != nil || limit != limits[0] {
+		t.Fatalf("LimitOf(struct) = %p, %v", limit, err)
+	}
+
+	query, err := limits[0].Compile([]*search.Searcher{
+		{Field: "name", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "yao"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	datasOut, err := query.Filter([]interface{}{&RegistryStruct{ID: 1, Name: "wzyao"}, &RegistryStruct{ID: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(datasOut) != 1 {
+		t.Fatalf("got %d datas", len(datasOut))
+	}
+
+	// the error is cached as well
+	for i := 0; i < 2; i++ {
+		_, err = search.LimitFor[int]()
+		fmt.Println(err)
+		if err == nil {
+			t.Fatal("int has no limit")
+		}
+	}
+	if _, err = search.LimitOf(nil); err == nil {
+		t.Fatal("nil has no limit")
+	}
+}
+
+func TestSearchLimitFor(t *testing.T) {
+	limit, err := search.LimitFor[SimpleStruct]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the shared limit filters like the one built by NewSearcherLimit
+	datasIn := []interface{}{
+		&SimpleStruct{A: 1, B: 10, Str: "wzyao1"},
+		&SimpleStruct{A: 2, B: 20, Str: "wzyao2"},
+		&SimpleStruct{A: 3, B: 30, Str: "wzyao3"},
+	}
+	searchers := []*search.Searcher{
+		{Field: "a", SearchOperator: search.SEARCH_OPERATOR_GREATER_EQUAL, Value: "2"},
+		{Field: "str", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "yao"},
+	}
+	query, err := limit.Compile(searchers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	datasOut, err := query.Filter(datasIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := searchLimit.Compile(searchers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectOut, err := expect.Filter(datasIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit == searchLimit || len(datasOut) != 2 || len(expectOut) != 2 ||
+		datasOut[0] != expectOut[0] || datasOut[1] != expectOut[1] {
+		t.Fatalf("unexpected datas %v %v", datasOut, expectOut)
+	}
+}
+
+func TestSearchLimitForSetTokenizer(t *testing.T) {
+	shared, err := search.LimitFor[Article]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the shared limit is not changed, the copy uses the new tokenizer
+	limit, err := shared.SetTokenizer("body", search.NGramTokenizer{N: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	datasIn := []interface{}{&Article{ID: 1, Body: "golang"}}
+	searchers := []*search.Searcher{{Field: "body", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "lan"}}
+	for _, c := range []struct {
+		limit *search.SearcherLimit
+		count int
+	}{{shared, 0}, {limit, 1}} {
+		query, err := c.limit.Compile(searchers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		datasOut, err := query.Filter(datasIn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(datasOut) != c.count {
+			t.Fatalf("expect %d datas, got %v", c.count, datasOut)
+		}
+	}
+	if again, err := search.LimitFor[Article](); err != nil || again != shared {
+		t.Fatalf("LimitFor = %p, %v", again, err)
+	}
+}
+
+func BenchmarkSearchLimitFor(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := search.LimitFor[SimpleStruct](); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"fmt"
+	"go_tests/search"
+	"testing"
+)
+
+const citySchemaYAML = `
+fields:
+  - name: city
+    type: string
+    operators: [eq, in, contain]
+  - name: population
+    type: int64
+    operators: [lt, lte, gt, gte]
+  - name: area
+    type: float64
+    operators: [gt]
+`
+
+const citySchemaJSON = `{"fields": [
+	{"name": "city", "type": "string", "operators": ["eq", "in", "contain"]},
+	{"name": "population", "type": "int64", "operators": ["lt", "lte", "gt", "gte"]},
+	{"name": "area", "type": "float64", "operators": ["gt"]}
+]}`
+
+func TestSearchSchema(t *testing.T) {
+	// values of csv rows are strings, values of sql scans are typed
+	rows := []map[string]interface{}{
+		{"city": "shanghai", "population": "24870895", "area": "6340.5"},
+		{"city": "beijing", "population": int64(21893095), "area": 16410.54},
+		{"city": "hangzhou", "population": 11936010, "area": "16850"},
+		{"city": "suzhou", "population": "unknown", "area": "8657.32"},
+	}
+	loads := []struct {
+		load func([]byte) (*search.Schema, error)
+		data string
+	}{
+		{search.LoadSchemaYAML, citySchemaYAML},
+		{search.LoadSchemaJSON, citySchemaJSON},
+	}
+	for _, load := range loads {
+		schema, err := load.load([]byte(load.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		limit, err := search.NewSearcherLimitFromSchema(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rowsOut, err := limit.FilterMaps([]*search.Searcher{
+			{Field: "population", SearchOperator: search.SEARCH_OPERATOR_GREATER, Value: "15000000"},
+			{Field: "area", SearchOperator: search.SEARCH_OPERATOR_GREATER, Value: "6000"},
+		}, rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println(rowsOut)
+		if len(rowsOut) != 2 || rowsOut[0]["city"] != "shanghai" || rowsOut[1]["city"] != "beijing" {
+			t.Fatalf("unexpected rows %v", rowsOut)
+		}
+		if _, err = limit.FilterMaps([]*search.Searcher{
+			{Field: "area", SearchOperator: search.SEARCH_OPERATOR_LESS, Value: "1"},
+		}, rows); err == nil {
+			t.Fatal("area only supports gt")
+		}
+	}
+
+	schema := &search.Schema{Fields: []search.SchemaField{
+		{Name: "city", Type: "string", Operators: []string{"match"}, Tokenizer: "ngram:3"},
+		{Name: "population", Type: "int64", Operators: []string{"contain"}},
+	}}
+	if _, err := search.NewSearcherLimitFromSchema(schema); err == nil {
+		t.Fatal("contain is not allowed for int64")
+	} else {
+		fmt.Println(err)
+	}
+	// bool is rejected rather than accepted without any operator
+	boolSchema := &search.Schema{Fields: []search.SchemaField{{Name: "active", Type: "bool", Operators: []string{"eq"}}}}
+	if _, err := search.NewSearcherLimitFromSchema(boolSchema); err == nil {
+		t.Fatal("bool is not supported")
+	} else {
+		fmt.Println(err)
+	}
+	schema.Fields = schema.Fields[:1]
+	limit, err := search.NewSearcherLimitFromSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rowsOut, err := limit.FilterMaps([]*search.Searcher{
+		{Field: "city", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "zhou"},
+	}, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rowsOut) != 2 {
+		t.Fatalf("unexpected rows %v", rowsOut)
+	}
+}
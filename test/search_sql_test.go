@@ -0,0 +1,166 @@
+package test
+
+import (
+	"database/sql"
+	"fmt"
+	"go_tests/search"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSearchToSQL(t *testing.T) {
+	limit, err := search.NewSearcherLimit(&Article{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	searchers := []*search.Searcher{
+		{Field: "id", SearchOperator: search.SEARCH_OPERATOR_IN, Value: "1,2,3"},
+		{Field: "title", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "50%_off"},
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_NOT_CONTAIN, Value: "rain"},
+	}
+	cases := []struct {
+		dialect search.Dialect
+		where   string
+		args    string
+	}{
+		{
+			search.DIALECT_MYSQL, "`article_id` IN (?, ?, ?) AND `article_title` LIKE ? AND `body` NOT LIKE ?",
+			`[1 2 3 %50\%\_off% %rain%]`,
+		},
+		{
+			search.DIALECT_POSTGRES, `"article_id" IN ($1, $2, $3) AND "article_title" LIKE $4 AND "body" NOT LIKE $5`,
+			`[1 2 3 %50\%\_off% %rain%]`,
+		},
+		{
+			search.DIALECT_SQLITE, `"article_id" IN (?, ?, ?) AND instr("article_title", ?) > 0 AND instr("body", ?) = 0`,
+			`[1 2 3 50%_off rain]`,
+		},
+	}
+	for _, cs := range cases {
+		where, args, err := limit.ToSQL(searchers, cs.dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println(where, args)
+		if where != cs.where {
+			t.Errorf("dialect(%d) where is %s, want %s", cs.dialect, where, cs.where)
+		}
+		if fmt.Sprint(args) != cs.args {
+			t.Errorf("dialect(%d) unexpected args %v", cs.dialect, args)
+		}
+	}
+	_, _, err = limit.ToSQL([]*search.Searcher{
+		{Field: "title", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "上海"},
+	}, search.DIALECT_MYSQL)
+	if err == nil {
+		t.Fatal("match should not be translated")
+	}
+	fmt.Println(err)
+}
+
+func TestSearchToSQLRegex(t *testing.T) {
+	limit, err := search.NewSearcherLimit(&Article{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	where, args, err := limit.ToSQL([]*search.Searcher{
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_REGEX, Value: `^go\s+\d+[a-z]*\\p$`},
+	}, search.DIALECT_POSTGRES)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(where, args)
+	if where != `"body" ~ $1` || args[0] != `^go\s+\d+[a-z]*\\p$` {
+		t.Fatalf("unexpected where %s %v", where, args)
+	}
+	// the syntax read differently by mysql or postgres is rejected
+	for _, pattern := range []string{`(?i)go`, `\bgo\b`, `\pL+`, `\Qa.b\E`, `(?P<word>go)`, `go\z`} {
+		_, _, err = limit.ToSQL([]*search.Searcher{
+			{Field: "body", SearchOperator: search.SEARCH_OPERATOR_REGEX, Value: pattern},
+		}, search.DIALECT_MYSQL)
+		fmt.Println(err)
+		if err == nil {
+			t.Fatalf("%s should not be translated", pattern)
+		}
+	}
+}
+
+func TestSearchToSQLite(t *testing.T) {
+	limit, err := search.NewSearcherLimit(&Article{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err = db.Exec(`CREATE TABLE article (article_id INTEGER, article_title TEXT, body TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	articles := []*Article{
+		{ID: 1, Title: "50% off today", Body: "sunny"},
+		{ID: 2, Title: "50_off", Body: "rain"},
+		{ID: 3, Title: "learn golang", Body: "go in action"},
+		{ID: 4, Title: "golang 100%", Body: `C:\go\bin`},
+		{ID: 5, Title: "gopher", Body: "go go go"},
+		{ID: 6, Title: "Go[1]*?", Body: "GO"},
+	}
+	var all []interface{}
+	for _, article := range articles {
+		if _, err = db.Exec(`INSERT INTO article VALUES (?, ?, ?)`, article.ID, article.Title, article.Body); err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, article)
+	}
+	cases := [][]*search.Searcher{
+		{{Field: "title", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "0%"}},
+		{{Field: "title", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "_"}},
+		{{Field: "title", SearchOperator: search.SEARCH_OPERATOR_PREFIX, Value: "go"}},
+		{{Field: "title", SearchOperator: search.SEARCH_OPERATOR_FUZZY, Value: "gln"}},
+		{{Field: "body", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: `\go`}},
+		// contain and prefix are case-sensitive, fuzzy ignores case
+		{{Field: "body", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "GO"}},
+		{{Field: "body", SearchOperator: search.SEARCH_OPERATOR_NOT_CONTAIN, Value: "go"}},
+		{{Field: "title", SearchOperator: search.SEARCH_OPERATOR_PREFIX, Value: "Go[1]*"}},
+		{{Field: "title", SearchOperator: search.SEARCH_OPERATOR_PREFIX, Value: "G?"}},
+		{{Field: "title", SearchOperator: search.SEARCH_OPERATOR_FUZZY, Value: "GOL"}},
+		{
+			{Field: "id", SearchOperator: search.SEARCH_OPERATOR_GREATER, Value: "1"},
+			{Field: "id", SearchOperator: search.SEARCH_OPERATOR_NOT_EQUAL, Value: "4"},
+			{Field: "body", SearchOperator: search.SEARCH_OPERATOR_NOT_CONTAIN, Value: "rain"},
+		},
+	}
+	for k, searchers := range cases {
+		where, args, err := limit.ToSQL(searchers, search.DIALECT_SQLITE)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := db.Query(`SELECT article_id FROM article WHERE `+where+` ORDER BY article_id`, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int
+		for rows.Next() {
+			var id int
+			if err = rows.Scan(&id); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, id)
+		}
+		rows.Close()
+		datas, err := filterBy(limit, all, searchers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want []int
+		for _, data := range datas {
+			want = append(want, data.(*Article).ID)
+		}
+		fmt.Printf("cases[%d] %s => %v\n", k, where, got)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("cases[%d] sqlite got %v, want %v", k, got, want)
+		}
+	}
+}
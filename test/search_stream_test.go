@@ -0,0 +1,130 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"go_tests/search"
+	"testing"
+	"time"
+)
+
+// pageSeq an iterator over a paginated source, it records the number of fetched pages
+func pageSeq(pages [][]*SimpleStruct, fetched *int) search.Seq {
+	return func(yield func(data interface{}) bool) {
+		for _, page := range pages {
+			*fetched++
+			for _, data := range page {
+				if !yield(data) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func TestSearchFilterSeq(t *testing.T) {
+	pages := [][]*SimpleStruct{
+		{{A: 1, Str: "wzyao1"}, {A: 2, Str: "x"}},
+		{{A: 3, Str: "wzyao3"}, {A: 4, Str: "wzyao4"}},
+		{{A: 5, Str: "wzyao5"}},
+	}
+	searchers := []*search.Searcher{
+		{Field: "str", SearchOperator: search.SEARCH_OPERATOR_CONTAIN_OR, Value: "wzyao"},
+	}
+	// the consumer stops after 2 results, so the last page is never fetched
+	fetched := 0
+	var got []int
+	err := searchLimit.FilterSeq(searchers, pageSeq(pages, &fetched), func(data interface{}) bool {
+		got = append(got, data.(*SimpleStruct).A)
+		return len(got) < 2
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(got, fetched)
+	if len(got) != 2 || got[0] != 1 || got[1] != 3 || fetched != 2 {
+		t.Fatalf("got %v, fetched %d pages", got, fetched)
+	}
+
+	err = searchLimit.FilterSeq(searchers,
+		search.SliceSeq([]interface{}{&SimpleStruct{}, (*SimpleStruct)(nil)}),
+		func(interface{}) bool { return true })
+	fmt.Println(err)
+	if err == nil || err.Error() != "datasIn[1] is a nil pointer" {
+		t.Fatalf("err = %v", err)
+	}
+	err = searchLimit.FilterSeq([]*search.Searcher{{Field: "str_a", SearchOperator: search.SEARCH_OPERATOR_EQUAL}},
+		search.SliceSeq(nil), func(interface{}) bool { return true })
+	if err == nil {
+		t.Fatal("invalid searchers should fail")
+	}
+}
+
+func TestSearchFilterChan(t *testing.T) {
+	searchers := []*search.Searcher{
+		{Field: "a", SearchOperator: search.SEARCH_OPERATOR_GREATER, Value: "2"},
+	}
+	in := make(chan interface{})
+	go func() {
+		defer close(in)
+		for i := 1; i <= 5; i++ {
+			in <- &SimpleStruct{A: i}
+		}
+	}()
+	out, errc := searchLimit.FilterChan(context.Background(), searchers, in)
+	var got []int
+	for data := range out {
+		got = append(got, data.(*SimpleStruct).A)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(got)
+	if len(got) != 3 || got[0] != 3 || got[2] != 5 {
+		t.Fatalf("got %v", got)
+	}
+
+	// the producer never closes in, cancellation stops the filter
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	endless := make(chan interface{})
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case endless <- &SimpleStruct{A: i}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	out, errc = searchLimit.FilterChan(ctx, searchers, endless)
+	for data := range out {
+		if data.(*SimpleStruct).A >= 10 {
+			cancel()
+			break
+		}
+	}
+	for range out { // drain until the filter exits
+	}
+	select {
+	case err := <-errc:
+		fmt.Println(err)
+		if err != context.Canceled {
+			t.Fatalf("err = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("FilterChan does not stop after cancel")
+	}
+
+	in = make(chan interface{}, 1)
+	in <- &SearchStructNotExist{}
+	out, errc = searchLimit.FilterChan(context.Background(), searchers, in)
+	for range out {
+	}
+	if err := <-errc; err == nil || err.Error() != "datasIn[0]'s type is invalid" {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+// SearchStructNotExist a struct which is not registered by searchLimit
+type SearchStructNotExist struct{}
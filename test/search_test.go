@@ -50,3 +50,23 @@ func BenchmarkSearch(b *testing.B) {
 		}
 	}
 }
+
+// TestSearchGreater greater used to match every value not equal to the searcher's value
+func TestSearchGreater(t *testing.T) {
+	datasIn := []interface{}{
+		&SimpleStruct{A: 1, B: 10, Str: "wzyao1"},
+		&SimpleStruct{A: 2, B: 20, Str: "wzyao2"},
+		&SimpleStruct{A: 3, B: 30, Str: "wzyao3"},
+	}
+	searchs := []*search.Searcher{{Field: "a", SearchOperator: search.SEARCH_OPERATOR_GREATER, Value: "2"}}
+	if err := searchLimit.ValidCheck(searchs); err != nil {
+		t.Fatal(err)
+	}
+	datasOut, err := searchs[0].Filter(searchLimit, datasIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(datasOut) != 1 || datasOut[0].(*SimpleStruct).A != 3 {
+		t.Fatalf("unexpected datas %v", datasOut)
+	}
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"fmt"
+	"go_tests/search"
+	"strings"
+	"testing"
+)
+
+type UpdateStruct struct {
+	ID        int     `json:"id" search:"eq,in"`
+	Status    string  `json:"status" search:"eq,neq" update:"true"`
+	UpdatedAt int64   `json:"updated_at" search:"lt,gt"`
+	Score     float32 `json:"score" update:"true"`
+	Pinned    bool    `json:"pinned" update:"true"`
+	Owner     string  `json:"owner" search:"eq"`
+}
+
+func updateDatas() []interface{} {
+	return []interface{}{
+		&UpdateStruct{ID: 1, Status: "active", UpdatedAt: 100},
+		&UpdateStruct{ID: 2, Status: "active", UpdatedAt: 300},
+		&UpdateStruct{ID: 3, Status: "archived", UpdatedAt: 50},
+		&UpdateStruct{ID: 4, Status: "active", UpdatedAt: 150, Pinned: true},
+	}
+}
+
+func TestSearchUpdate(t *testing.T) {
+	limit, err := search.LimitFor[UpdateStruct]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	datas := updateDatas()
+	// set status=archived where updated_at < 200
+	changed, err := limit.Update(datas, []*search.Searcher{
+		{Field: "updated_at", SearchOperator: search.SEARCH_OPERATOR_LESS, Value: "200"},
+	}, []*search.Assignment{{Field: "status", Value: "archived"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println("changed:", changed)
+	// the 3rd data is archived already
+	if changed != 2 {
+		t.Fatalf("expect 2 changed datas, got %d", changed)
+	}
+	for _, data := range datas {
+		d := data.(*UpdateStruct)
+		if (d.UpdatedAt < 200) != (d.Status == "archived") {
+			t.Fatalf("unexpected status of %+v", *d)
+		}
+	}
+
+	query, err := limit.Compile([]*search.Searcher{
+		{Field: "status", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "archived"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err = query.Update(datas, []*search.Assignment{
+		{Field: "score", Value: "1.5"}, {Field: "pinned", Value: "true"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != 3 {
+		t.Fatalf("expect 3 changed datas, got %d", changed)
+	}
+	if d := datas[3].(*UpdateStruct); d.Score != 1.5 || !d.Pinned {
+		t.Fatalf("unexpected data %+v", *d)
+	}
+	if d := datas[1].(*UpdateStruct); d.Score != 0 || d.Pinned {
+		t.Fatalf("unmatched data is changed %+v", *d)
+	}
+}
+
+func TestSearchUpdateInvalid(t *testing.T) {
+	limit, err := search.LimitFor[UpdateStruct]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	where := []*search.Searcher{{Field: "id", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "1"}}
+	cases := []struct {
+		datas       []interface{}
+		assignments []*search.Assignment
+		expect      string
+	}{
+		{updateDatas(), nil, "assignments is empty"},
+		{updateDatas(), []*search.Assignment{{Field: "owner", Value: "tom"}}, "field(owner) does not support update"},
+		{updateDatas(), []*search.Assignment{{Field: "score", Value: "x"}}, "assignments[0] is invalid"},
+		{updateDatas(), []*search.Assignment{{Field: "pinned", Value: "true"}, {Field: "pinned", Value: "false"}},
+			"field(pinned) is assigned more than once"},
+		{append(updateDatas(), (*UpdateStruct)(nil)), []*search.Assignment{{Field: "status", Value: "x"}},
+			"datasIn[4] is a nil pointer"},
+		{append(updateDatas(), &SimpleStruct{}), []*search.Assignment{{Field: "status", Value: "x"}},
+			"datasIn[4]'s type is invalid"},
+	}
+	for k, c := range cases {
+		changed, err := limit.Update(c.datas, where, c.assignments)
+		fmt.Println(k, changed, err)
+		if err == nil || !strings.HasPrefix(err.Error(), c.expect) {
+			t.Fatalf("case %d: expect error %q, got %v", k, c.expect, err)
+		}
+		// nothing is modified when the input is invalid
+		if d := c.datas[0].(*UpdateStruct); d.Status != "active" || d.Pinned || d.Score != 0 {
+			t.Fatalf("case %d: data is modified %+v", k, *d)
+		}
+	}
+
+	type badStruct struct {
+		Tags []string `json:"tags" update:"true"`
+	}
+	if _, err = search.NewSearcherLimit(&badStruct{}); err == nil {
+		t.Fatal("expect an error for an update field of slice")
+	}
+	// rejected in both modes, reflect can not set it under the search_safe tag
+	type unexportedStruct struct {
+		status string `update:"true"`
+	}
+	_, err = search.NewSearcherLimit(&unexportedStruct{})
+	fmt.Println(err)
+	if err == nil || err.Error() != "field(status) tagged update must be exported" {
+		t.Fatalf("expect an error for an unexported update field, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go_tests/search"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSearchValidCheckAll(t *testing.T) {
+	limit, err := search.LimitFor[Article]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	searchers := []*search.Searcher{
+		{Field: "not_exist", SearchOperator: search.SEARCH_OPERATOR_EQUAL, Value: "1"},
+		{Field: "title", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "go"},
+		nil,
+		{Field: "title", SearchOperator: search.SEARCH_OPERATOR_LESS, Value: "go"},
+		{Field: "body", SearchOperator: search.SEARCH_OPERATOR_REGEX, Value: "("},
+	}
+	query, err := limit.ValidCheckAll(searchers)
+	fmt.Println(err)
+	if query != nil {
+		t.Fatal("expect no query for invalid searchers")
+	}
+	var errs search.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expect search.ValidationErrors, got %v", err)
+	}
+	var indexes []int
+	for _, ve := range errs {
+		indexes = append(indexes, ve.Index)
+	}
+	if !reflect.DeepEqual(indexes, []int{0, 2, 3, 4}) {
+		t.Fatalf("unexpected invalid searchers %v", indexes)
+	}
+	if errs[0].Reason != "field(not_exist) does not support search" || errs[0].Allowed != nil {
+		t.Fatalf("unexpected error %+v", *errs[0])
+	}
+	if errs[2].Field != "title" || errs[2].Operator != "less than" || len(errs[2].Allowed) == 0 {
+		t.Fatalf("unexpected error %+v", *errs[2])
+	}
+	if errs[3].Reason != "error parsing regexp: missing closing ): `(`" {
+		t.Fatalf("unexpected error %+v", *errs[3])
+	}
+	// ValidCheck reports the first one only
+	if err = limit.ValidCheck(searchers); err == nil || err.Error() != errs[0].Reason {
+		t.Fatalf("unexpected error of ValidCheck %v", err)
+	}
+
+	data, err := json.Marshal(errs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(string(data))
+	var decoded []map[string]interface{}
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 4 || decoded[2]["operator"] != "less than" || decoded[2]["allowed"] == nil {
+		t.Fatalf("unexpected json %s", data)
+	}
+
+	st := status.Convert(errs)
+	if st.Code() != codes.InvalidArgument || len(st.Details()) == 0 {
+		t.Fatalf("unexpected status %v", st)
+	}
+	badRequest := st.Details()[0].(*errdetails.BadRequest)
+	if len(badRequest.FieldViolations) != 4 || badRequest.FieldViolations[2].Field != "searchers[3].title" ||
+		badRequest.FieldViolations[1].Field != "searchers[2]" {
+		t.Fatalf("unexpected details %v", badRequest)
+	}
+	// the unsupported operator is described with the allowed ones
+	if len(st.Details()) != 2 {
+		t.Fatalf("unexpected details %v", st.Details())
+	}
+	info := st.Details()[1].(*errdetails.ErrorInfo)
+	fmt.Println(info)
+	if info.Metadata["searcher"] != "searchers[3].title" || info.Metadata["operator"] != "less than" ||
+		info.Metadata["allowed"] != strings.Join(errs[2].Allowed, "/") {
+		t.Fatalf("unexpected error info %v", info)
+	}
+
+	// the valid searchers are compiled into a query
+	valid := []*search.Searcher{{Field: "title", SearchOperator: search.SEARCH_OPERATOR_MATCH, Value: "go"}}
+	if query, err = limit.ValidCheckAll(valid); err != nil {
+		t.Fatal(err)
+	}
+	if matched, err := query.Match(&Article{Title: "Go 语言"}); err != nil || !matched {
+		t.Fatalf("unexpected match %v %v", matched, err)
+	}
+}
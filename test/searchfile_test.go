@@ -0,0 +1,96 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go_tests/cmd/searchfile/cli"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const searchfileCSV = "name,age,city\ntom,20,shanghai\namy,15,beijing\nbob,30,\"new york\"\n"
+
+func TestSearchfileRun(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "users.csv")
+	if err := os.WriteFile(csvPath, []byte(searchfileCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	jsonlPath := filepath.Join(dir, "users.jsonl")
+	jsonl := "{\"name\":\"tom\",\"age\":20}\n\n{\"name\":\"amy\",\"age\":15}\n"
+	if err := os.WriteFile(jsonlPath, []byte(jsonl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	extraPath := filepath.Join(dir, "extra.jsonl")
+	if err := os.WriteFile(extraPath, []byte(jsonl+"{\"name\":\"bob\",\"city\":\"x\"}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	schemaPath := filepath.Join(dir, "schema.yaml")
+	schema := "fields:\n  - name: age\n    type: int\n    operators: [lt, gte]\n"
+	if err := os.WriteFile(schemaPath, []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		args  []string
+		stdin string
+		want  string
+	}{
+		{
+			[]string{"-in", csvPath, "-query", "age >= 18 and city != beijing"}, "",
+			"name,age,city\ntom,20,shanghai\nbob,30,new york\n",
+		},
+		// stdin is read as csv by default
+		{[]string{"-query", "age < 18", "-out", "jsonl"}, searchfileCSV, `{"name":"amy","age":"15","city":"beijing"}` + "\n"},
+		{[]string{"-in", jsonlPath, "-query", "age >= 18"}, "", `{"name":"tom","age":20}` + "\n"},
+		{[]string{"-in", jsonlPath, "-query", "name = amy", "-out", "csv"}, "", "age,name\n15,amy\n"},
+		{[]string{"-in", csvPath, "-schema", schemaPath, "-query", "age < 18", "-out", "table"}, "",
+			"name  age  city\namy   15   beijing\n"},
+		{[]string{"-format", "jsonl", "-query", "age >= 18"}, jsonl, `{"name":"tom","age":20}` + "\n"},
+	}
+	for k, c := range cases {
+		var out bytes.Buffer
+		if err := cli.Run(c.args, strings.NewReader(c.stdin), &out); err != nil {
+			t.Fatalf("cases[%d] %s", k, err)
+		}
+		fmt.Print(out.String())
+		if out.String() != c.want {
+			t.Errorf("cases[%d] got %q, want %q", k, out.String(), c.want)
+		}
+	}
+
+	errCases := []struct {
+		args    []string
+		flagErr bool
+	}{
+		{[]string{"-nope"}, true},
+		{[]string{"-sample", "x"}, true},
+		{[]string{"-in", csvPath, "extra"}, true},
+		{[]string{"-in", csvPath, "-out", "xml"}, true},
+		{[]string{"-in", filepath.Join(dir, "missing.csv")}, false},
+		{[]string{"-in", csvPath, "-query", "age >>= 1"}, false},
+		{[]string{"-in", csvPath, "-query", "name < tom"}, false},
+		{[]string{"-in", csvPath, "-schema", filepath.Join(dir, "missing.yaml")}, false},
+		// the key city is out of the sample rows
+		{[]string{"-in", extraPath, "-sample", "2", "-query", "name != x", "-out", "table"}, false},
+	}
+	for k, c := range errCases {
+		var out bytes.Buffer
+		err := cli.Run(c.args, strings.NewReader(""), &out)
+		fmt.Println(err)
+		if err == nil {
+			t.Fatalf("errCases[%d] expect an error", k)
+		}
+		var flagErr *cli.FlagError
+		if errors.As(err, &flagErr) != c.flagErr {
+			t.Errorf("errCases[%d] unexpected error type %T", k, err)
+		}
+	}
+	if err := cli.Run([]string{"-h"}, strings.NewReader(""), &bytes.Buffer{}); err != flag.ErrHelp {
+		t.Fatalf("expect flag.ErrHelp, got %v", err)
+	}
+}
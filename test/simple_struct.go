@@ -1,8 +1,10 @@
 package test
 
+//go:generate go run go_tests/cmd/searchgen -type SimpleStruct
+
 type SimpleStruct struct {
-	A    int    `json:"a" search:"lt,lte,eq,gte,gt,neq"`
+	A    int    `json:"a" search:"lt,lte,eq,gte,gt,neq,in"`
 	B    int    `json:"b" search:"lt,lte,eq,gte,gt,neq"`
-	Str  string `json:"str" search:"contain,notcontain"`
+	Str  string `json:"str" search:"contain,notcontain,eq,in"`
 	StrA string `json:"str_a"`
 }
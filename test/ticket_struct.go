@@ -0,0 +1,15 @@
+package test
+
+//go:generate go run go_tests/cmd/searchgen -type Ticket
+
+// TicketStatus a named type whose underlying type is searched
+type TicketStatus int32
+
+// TicketLabel a named string type
+type TicketLabel string
+
+type Ticket struct {
+	Status TicketStatus `json:"status" search:"eq,in,gt"`
+	Label  TicketLabel  `json:"label" search:"eq,prefix"`
+	Level  uint8        `json:"level" search:"lt,gte"`
+}